@@ -0,0 +1,175 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AggregationMode selects how votes are combined into the average drawn on
+// the chart.
+type AggregationMode string
+
+const (
+	// AggregateMean is the plain weighted mean of the level midpoints.
+	AggregateMean AggregationMode = "mean"
+	// AggregateTrimmed drops the TrimFraction most extreme votes at each
+	// end before taking the mean.
+	AggregateTrimmed AggregationMode = "trimmed"
+	// AggregateWinsorized moves the TrimFraction most extreme votes at each
+	// end onto the nearest remaining vote before taking the mean.
+	AggregateWinsorized AggregationMode = "winsorized"
+	// AggregateMedian is the median of the vote midpoints.
+	AggregateMedian AggregationMode = "median"
+	// AggregateBayesian pulls the mean toward a prior level, as if
+	// Aggregation.PriorWeight extra votes had been cast for it.
+	AggregateBayesian AggregationMode = "bayesian"
+)
+
+const (
+	// TrimFraction is the share of votes trimmed or winsorized at each end.
+	TrimFraction = 0.2
+	// DefaultPriorWeight is the number of votes the prior of
+	// AggregateBayesian is worth when Aggregation.PriorWeight is zero.
+	DefaultPriorWeight = 5
+)
+
+var aggregationLabels = map[AggregationMode]string{
+	AggregateMean:       "AVG",
+	AggregateTrimmed:    "TRIMMED AVG",
+	AggregateWinsorized: "WINSORIZED AVG",
+	AggregateMedian:     "MEDIAN AVG",
+	AggregateBayesian:   "BAYESIAN AVG",
+}
+
+// LookupAggregation returns the named mode. An empty name selects
+// AggregateMean.
+func LookupAggregation(name string) (AggregationMode, error) {
+	if name == "" {
+		return AggregateMean, nil
+	}
+	m := AggregationMode(name)
+	if _, ok := aggregationLabels[m]; !ok {
+		return "", fmt.Errorf("unknown aggregation: %s (available: %v)", name, AggregationNames())
+	}
+	return m, nil
+}
+
+func AggregationNames() []string {
+	names := make([]string, 0, len(aggregationLabels))
+	for m := range aggregationLabels {
+		names = append(names, string(m))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Label is the name of the average in the chart header.
+func (m AggregationMode) Label() string {
+	if label, ok := aggregationLabels[m]; ok {
+		return label
+	}
+	return aggregationLabels[AggregateMean]
+}
+
+// Aggregation configures how the chart's average is computed. The zero
+// value is the plain mean.
+type Aggregation struct {
+	Mode AggregationMode
+	// Prior is the level AggregateBayesian pulls toward, usually the
+	// difficulty the map was submitted as.
+	Prior string
+	// PriorWeight is how many votes Prior is worth; zero selects
+	// DefaultPriorWeight.
+	PriorWeight float64
+}
+
+// isMean reports whether the average is the plain mean, whose confidence
+// interval Stats reports.
+func (a Aggregation) isMean() bool {
+	return a.Mode == "" || a.Mode == AggregateMean
+}
+
+// Validate checks a against the scale the votes are cast on.
+func (a Aggregation) Validate(sc *Scale) error {
+	mode, err := LookupAggregation(string(a.Mode))
+	if err != nil {
+		return err
+	}
+	if a.Prior != "" {
+		if _, ok := sc.Index(a.Prior); !ok {
+			return fmt.Errorf("invalid submitted difficulty: %s", a.Prior)
+		}
+	}
+	if a.PriorWeight < 0 || math.IsNaN(a.PriorWeight) || math.IsInf(a.PriorWeight, 0) {
+		return errors.New("prior weight must be a non-negative number")
+	}
+	if mode == AggregateBayesian && a.Prior == "" {
+		return fmt.Errorf("%s aggregation needs a submitted difficulty", mode)
+	}
+	return nil
+}
+
+// Aggregate combines votes into a single value on the midpoint axis, so it
+// can be mapped to a level with AverageToLabel. a must be valid for s.
+func (s *Scale) Aggregate(votes map[string]int, a Aggregation) float64 {
+	return s.aggregate(s.TallyStats(TallyCounts(votes)), a)
+}
+
+func (s *Scale) aggregate(st Stats, a Aggregation) float64 {
+	switch a.Mode {
+	case AggregateTrimmed:
+		return s.trimmedMean(st.Shares, st.TotalVotes, false)
+	case AggregateWinsorized:
+		return s.trimmedMean(st.Shares, st.TotalVotes, true)
+	case AggregateMedian:
+		return st.Median
+	case AggregateBayesian:
+		w := a.PriorWeight
+		if w == 0 {
+			w = DefaultPriorWeight
+		}
+		n := st.TotalVotes
+		return (st.Mean*n + s.Midpoints[a.Prior]*w) / (n + w)
+	default:
+		return st.Mean
+	}
+}
+
+// trimmedMean cuts TrimFraction of the votes from each end of the sorted
+// votes and averages the rest. The cut may fall inside a level, in which
+// case only part of its votes are kept, so small polls are trimmed smoothly
+// rather than a whole vote at a time. With winsorize the cut votes are
+// counted at the first and last kept midpoints instead of being dropped.
+func (s *Scale) trimmedMean(shares []LevelShare, total float64, winsorize bool) float64 {
+	if total == 0 {
+		return 0
+	}
+	// eps keeps a level that ends exactly at a cut, give or take float
+	// noise, from counting as kept.
+	const eps = 1e-9
+	cut := TrimFraction * total
+	lo, hi := cut, total-cut
+
+	var sum, start, first, last float64
+	kept := false
+	for _, sh := range shares {
+		end := start + sh.Votes
+		w := math.Min(end, hi) - math.Max(start, lo)
+		start = end
+		if w <= eps {
+			continue
+		}
+		mid := s.Midpoints[sh.Level]
+		if !kept {
+			first, kept = mid, true
+		}
+		last = mid
+		sum += mid * w
+	}
+	if winsorize {
+		return (sum + cut*(first+last)) / total
+	}
+	return sum / (hi - lo)
+}
@@ -0,0 +1,96 @@
+package chart
+
+import "testing"
+
+func TestAggregate(t *testing.T) {
+	// Eight Medium votes and two trolls at Hell: a 20% trim removes both
+	// trolls and the two lowest Medium votes.
+	trolled := map[string]int{"Medium": 8, "Hell": 2}
+	mean := (8*3.23 + 2*9.71) / 10
+
+	tests := []struct {
+		name  string
+		votes map[string]int
+		agg   Aggregation
+		want  float64
+	}{
+		{"zero value is the mean", trolled, Aggregation{}, mean},
+		{"mean", trolled, Aggregation{Mode: AggregateMean}, mean},
+		{"trimmed drops the trolls", trolled, Aggregation{Mode: AggregateTrimmed}, 3.23},
+		{"winsorized moves the trolls", trolled, Aggregation{Mode: AggregateWinsorized}, 3.23},
+		{"median", trolled, Aggregation{Mode: AggregateMedian}, 3.23},
+		{
+			"bayesian default weight",
+			trolled,
+			Aggregation{Mode: AggregateBayesian, Prior: "Medium"},
+			(8*3.23 + 2*9.71 + DefaultPriorWeight*3.23) / (10 + DefaultPriorWeight),
+		},
+		{
+			"bayesian custom weight",
+			trolled,
+			Aggregation{Mode: AggregateBayesian, Prior: "Easy", PriorWeight: 10},
+			(8*3.23 + 2*9.71 + 10*1.47) / 20,
+		},
+		{
+			// 5 votes cut 1 from each end: Easy and one Hard go, leaving
+			// Medium, Hard, Hard.
+			"trimmed small poll",
+			map[string]int{"Easy": 1, "Medium": 1, "Hard": 3},
+			Aggregation{Mode: AggregateTrimmed},
+			(3.23 + 2*5.0) / 3,
+		},
+		{
+			"winsorized small poll",
+			map[string]int{"Easy": 1, "Medium": 1, "Hard": 3},
+			Aggregation{Mode: AggregateWinsorized},
+			(2*3.23 + 3*5.0) / 5,
+		},
+		{
+			// 4 votes cut 0.8 from each end, keeping 0.2 of the Easy vote
+			// and 0.2 of the Hard vote.
+			"trimmed splits a level at the cut",
+			map[string]int{"Easy": 1, "Medium": 2, "Hard": 1},
+			Aggregation{Mode: AggregateTrimmed},
+			(0.2*1.47 + 2*3.23 + 0.2*5.0) / 2.4,
+		},
+		{
+			"winsorized splits a level at the cut",
+			map[string]int{"Easy": 1, "Medium": 2, "Hard": 1},
+			Aggregation{Mode: AggregateWinsorized},
+			(1.47 + 2*3.23 + 5.0) / 4,
+		},
+		{"unanimous", map[string]int{"Hard": 4}, Aggregation{Mode: AggregateTrimmed}, 5.0},
+		{"no votes", map[string]int{}, Aggregation{Mode: AggregateWinsorized}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenjiScale.Aggregate(tt.votes, tt.agg); !approx(got, tt.want) {
+				t.Errorf("Aggregate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregationValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		agg     Aggregation
+		wantErr bool
+	}{
+		{"zero value", Aggregation{}, false},
+		{"trimmed", Aggregation{Mode: AggregateTrimmed}, false},
+		{"bayesian", Aggregation{Mode: AggregateBayesian, Prior: "Hard", PriorWeight: 2}, false},
+		{"prior without bayesian", Aggregation{Mode: AggregateMedian, Prior: "Hard"}, false},
+		{"unknown mode", Aggregation{Mode: "geometric"}, true},
+		{"bayesian without prior", Aggregation{Mode: AggregateBayesian}, true},
+		{"unknown prior", Aggregation{Mode: AggregateBayesian, Prior: "Trivial"}, true},
+		{"negative weight", Aggregation{Mode: AggregateBayesian, Prior: "Hard", PriorWeight: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.agg.Validate(GenjiScale); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+const (
+	FontBankSans = "Bank Sans EF CY"
+	FontInter    = "Inter"
+)
+
+// TextExtents mirrors cairo's text extents: bearings are measured from the
+// current point to the ink bounding box, with YBearing negative above the
+// baseline.
+type TextExtents struct {
+	XBearing float64
+	YBearing float64
+	Width    float64
+	Height   float64
+	XAdvance float64
+}
+
+// Canvas is the set of drawing primitives the chart renderer uses. Paths are
+// built with MoveTo/LineTo/Arc/Rectangle and consumed by Fill or Stroke, in
+// the same way as a cairo context.
+type Canvas interface {
+	SetSourceRGB(r, g, b float64)
+	SetSourceRGBA(r, g, b, a float64)
+
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	Arc(xc, yc, radius, angle1, angle2 float64)
+	Rectangle(x, y, width, height float64)
+	ClosePath()
+	Fill()
+	Stroke()
+
+	SetLineWidth(width float64)
+	SetDash(dashes []float64, offset float64)
+
+	SelectFont(family string, bold bool)
+	SetFontSize(size float64)
+	TextExtents(text string) TextExtents
+	ShowText(text string)
+
+	Save()
+	Restore()
+	Translate(tx, ty float64)
+}
+
+// RasterCanvas is a Canvas backed by an in-memory bitmap.
+type RasterCanvas interface {
+	Canvas
+	Image() image.Image
+	Close()
+}
+
+var backends = map[string]func(width, height int) RasterCanvas{}
+
+var activeBackend string
+
+func registerBackend(name string, newCanvas func(width, height int) RasterCanvas) {
+	backends[name] = newCanvas
+}
+
+// Backends lists the raster backends compiled into this binary.
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BackendName reports the raster backend used by RenderChart. Cairo is the
+// default whenever it is compiled in.
+func BackendName() string {
+	if activeBackend != "" {
+		return activeBackend
+	}
+	if _, ok := backends["cairo"]; ok {
+		return "cairo"
+	}
+	return "go"
+}
+
+// SetBackend selects the raster backend by name. It is not safe to call
+// while charts are being rendered and is meant to be used at startup.
+func SetBackend(name string) error {
+	if _, ok := backends[name]; !ok {
+		return fmt.Errorf("unknown chart backend %q (available: %v)", name, Backends())
+	}
+	activeBackend = name
+	return nil
+}
+
+func newRasterCanvas(width, height int) RasterCanvas {
+	return backends[BackendName()](width, height)
+}
@@ -0,0 +1,93 @@
+//go:build !purego
+
+package chart
+
+import (
+	"image"
+
+	"github.com/ungerik/go-cairo"
+)
+
+func init() {
+	registerBackend("cairo", func(width, height int) RasterCanvas {
+		return newCairoCanvas(cairo.NewSurface(cairo.FORMAT_ARGB32, width, height))
+	})
+}
+
+// cairoCanvas adapts a cairo surface to the Canvas interface.
+type cairoCanvas struct {
+	surface *cairo.Surface
+}
+
+func newCairoCanvas(surface *cairo.Surface) *cairoCanvas {
+	return &cairoCanvas{surface: surface}
+}
+
+func (c *cairoCanvas) SetSourceRGB(r, g, b float64)     { c.surface.SetSourceRGB(r, g, b) }
+func (c *cairoCanvas) SetSourceRGBA(r, g, b, a float64) { c.surface.SetSourceRGBA(r, g, b, a) }
+func (c *cairoCanvas) MoveTo(x, y float64)              { c.surface.MoveTo(x, y) }
+func (c *cairoCanvas) LineTo(x, y float64)              { c.surface.LineTo(x, y) }
+func (c *cairoCanvas) Rectangle(x, y, w, h float64)     { c.surface.Rectangle(x, y, w, h) }
+func (c *cairoCanvas) ClosePath()                       { c.surface.ClosePath() }
+func (c *cairoCanvas) Fill()                            { c.surface.Fill() }
+func (c *cairoCanvas) Stroke()                          { c.surface.Stroke() }
+func (c *cairoCanvas) SetLineWidth(width float64)       { c.surface.SetLineWidth(width) }
+func (c *cairoCanvas) SetFontSize(size float64)         { c.surface.SetFontSize(size) }
+func (c *cairoCanvas) ShowText(text string)             { c.surface.ShowText(text) }
+func (c *cairoCanvas) Save()                            { c.surface.Save() }
+func (c *cairoCanvas) Restore()                         { c.surface.Restore() }
+func (c *cairoCanvas) Translate(tx, ty float64)         { c.surface.Translate(tx, ty) }
+
+func (c *cairoCanvas) Arc(xc, yc, radius, angle1, angle2 float64) {
+	c.surface.Arc(xc, yc, radius, angle1, angle2)
+}
+
+func (c *cairoCanvas) SetDash(dashes []float64, offset float64) {
+	c.surface.SetDash(dashes, len(dashes), offset)
+}
+
+func (c *cairoCanvas) SelectFont(family string, bold bool) {
+	weight := cairo.FONT_WEIGHT_NORMAL
+	if bold {
+		weight = cairo.FONT_WEIGHT_BOLD
+	}
+	c.surface.SelectFontFace(family, cairo.FONT_SLANT_NORMAL, weight)
+}
+
+func (c *cairoCanvas) TextExtents(text string) TextExtents {
+	e := c.surface.TextExtents(text)
+	return TextExtents{
+		XBearing: e.Xbearing,
+		YBearing: e.Ybearing,
+		Width:    e.Width,
+		Height:   e.Height,
+		XAdvance: e.Xadvance,
+	}
+}
+
+func (c *cairoCanvas) Image() image.Image {
+	c.surface.Flush()
+	return surfaceToImage(c.surface)
+}
+
+func (c *cairoCanvas) Close() {
+	c.surface.Finish()
+}
+
+func surfaceToImage(surface *cairo.Surface) *image.RGBA {
+	width := surface.GetWidth()
+	height := surface.GetHeight()
+	data := surface.GetData()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	pixelCount := width * height
+	for i := 0; i < pixelCount; i++ {
+		off := i * 4
+		img.Pix[off] = data[off+2]
+		img.Pix[off+1] = data[off+1]
+		img.Pix[off+2] = data[off]
+		img.Pix[off+3] = data[off+3]
+	}
+	return img
+}
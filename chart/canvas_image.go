@@ -0,0 +1,495 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/genjishimada/playtest-plotter/fonts"
+)
+
+func init() {
+	registerBackend("go", func(width, height int) RasterCanvas {
+		return newImageCanvas(width, height)
+	})
+}
+
+// arcTolerance is the maximum distance, in pixels, between a flattened arc
+// and the true curve.
+const arcTolerance = 0.1
+
+// boldOffset is the horizontal smear used to synthesize a bold weight, as the
+// bundled fonts ship a single weight each.
+const boldOffset = 0.6
+
+var embeddedFonts = sync.OnceValue(func() map[string]*sfnt.Font {
+	parsed := map[string]*sfnt.Font{}
+	for family, data := range map[string][]byte{
+		FontBankSans: fonts.BankSans,
+		FontInter:    fonts.Inter,
+	} {
+		f, err := opentype.Parse(data)
+		if err != nil {
+			panic("failed to parse embedded font " + family + ": " + err.Error())
+		}
+		parsed[family] = f
+	}
+	return parsed
+})
+
+type point struct {
+	x, y float64
+}
+
+type subpath struct {
+	points []point
+	closed bool
+}
+
+type canvasState struct {
+	color      color.NRGBA
+	lineWidth  float64
+	dashes     []float64
+	dashOffset float64
+	family     string
+	bold       bool
+	fontSize   float64
+	tx, ty     float64
+}
+
+type faceKey struct {
+	family string
+	size   float64
+}
+
+// imageCanvas is a pure-Go Canvas that rasterizes onto an *image.RGBA with
+// golang.org/x/image/vector and draws text from the embedded OpenType fonts.
+type imageCanvas struct {
+	img    *image.RGBA
+	state  canvasState
+	stack  []canvasState
+	path   []subpath
+	cur    point
+	hasCur bool
+	raster vector.Rasterizer
+	faces  map[faceKey]font.Face
+}
+
+func newImageCanvas(width, height int) *imageCanvas {
+	return &imageCanvas{
+		img: image.NewRGBA(image.Rect(0, 0, width, height)),
+		state: canvasState{
+			color:     color.NRGBA{0, 0, 0, 255},
+			lineWidth: 2,
+			family:    FontInter,
+			fontSize:  10,
+		},
+		faces: map[faceKey]font.Face{},
+	}
+}
+
+func (c *imageCanvas) SetSourceRGB(r, g, b float64) {
+	c.SetSourceRGBA(r, g, b, 1)
+}
+
+func (c *imageCanvas) SetSourceRGBA(r, g, b, a float64) {
+	c.state.color = color.NRGBA{unitToByte(r), unitToByte(g), unitToByte(b), unitToByte(a)}
+}
+
+func unitToByte(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+func (c *imageCanvas) device(x, y float64) point {
+	return point{x + c.state.tx, y + c.state.ty}
+}
+
+func (c *imageCanvas) MoveTo(x, y float64) {
+	p := c.device(x, y)
+	c.path = append(c.path, subpath{points: []point{p}})
+	c.cur, c.hasCur = p, true
+}
+
+func (c *imageCanvas) LineTo(x, y float64) {
+	c.lineToDevice(c.device(x, y))
+}
+
+func (c *imageCanvas) lineToDevice(p point) {
+	if !c.hasCur {
+		c.path = append(c.path, subpath{points: []point{p}})
+		c.cur, c.hasCur = p, true
+		return
+	}
+	last := &c.path[len(c.path)-1]
+	if last.closed {
+		c.path = append(c.path, subpath{points: []point{c.cur}})
+		last = &c.path[len(c.path)-1]
+	}
+	last.points = append(last.points, p)
+	c.cur = p
+}
+
+func (c *imageCanvas) Arc(xc, yc, radius, angle1, angle2 float64) {
+	for angle2 < angle1 {
+		angle2 += 2 * math.Pi
+	}
+	center := c.device(xc, yc)
+	start := point{center.x + radius*math.Cos(angle1), center.y + radius*math.Sin(angle1)}
+	if c.hasCur {
+		c.lineToDevice(start)
+	} else {
+		c.path = append(c.path, subpath{points: []point{start}})
+		c.cur, c.hasCur = start, true
+	}
+
+	segments := arcSegments(radius, angle2-angle1)
+	for i := 1; i <= segments; i++ {
+		a := angle1 + (angle2-angle1)*float64(i)/float64(segments)
+		c.lineToDevice(point{center.x + radius*math.Cos(a), center.y + radius*math.Sin(a)})
+	}
+}
+
+func arcSegments(radius, sweep float64) int {
+	if radius <= arcTolerance {
+		return 1
+	}
+	maxStep := 2 * math.Acos(1-arcTolerance/radius)
+	n := int(math.Ceil(math.Abs(sweep) / maxStep))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (c *imageCanvas) Rectangle(x, y, width, height float64) {
+	c.MoveTo(x, y)
+	c.LineTo(x+width, y)
+	c.LineTo(x+width, y+height)
+	c.LineTo(x, y+height)
+	c.ClosePath()
+}
+
+func (c *imageCanvas) ClosePath() {
+	if len(c.path) == 0 {
+		return
+	}
+	last := &c.path[len(c.path)-1]
+	last.closed = true
+	c.cur = last.points[0]
+}
+
+func (c *imageCanvas) newPath() {
+	c.path = c.path[:0]
+	c.hasCur = false
+}
+
+func (c *imageCanvas) Fill() {
+	polys := make([][]point, 0, len(c.path))
+	for _, sp := range c.path {
+		if len(sp.points) >= 3 {
+			polys = append(polys, sp.points)
+		}
+	}
+	c.fillPolygons(polys)
+	c.newPath()
+}
+
+func (c *imageCanvas) Stroke() {
+	var polys [][]point
+	for _, sp := range c.path {
+		pts := sp.points
+		if sp.closed && len(pts) > 1 {
+			pts = append(append([]point{}, pts...), pts[0])
+		}
+		for _, dash := range dashPolyline(pts, c.state.dashes, c.state.dashOffset) {
+			polys = append(polys, strokePolyline(dash, c.state.lineWidth)...)
+		}
+	}
+	c.fillPolygons(polys)
+	c.newPath()
+}
+
+// fillPolygons rasterizes polys with the current source color. Every polygon
+// is normalized to the same winding so that overlapping pieces of a stroke
+// union rather than cancel out.
+func (c *imageCanvas) fillPolygons(polys [][]point) {
+	if len(polys) == 0 {
+		return
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for _, p := range poly {
+			minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+			maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+		}
+	}
+	bounds := image.Rect(
+		int(math.Floor(minX))-1, int(math.Floor(minY))-1,
+		int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1,
+	).Intersect(c.img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	ox, oy := float64(bounds.Min.X), float64(bounds.Min.Y)
+	c.raster.Reset(bounds.Dx(), bounds.Dy())
+	for _, poly := range polys {
+		if signedArea(poly) < 0 {
+			poly = reversed(poly)
+		}
+		c.raster.MoveTo(float32(poly[0].x-ox), float32(poly[0].y-oy))
+		for _, p := range poly[1:] {
+			c.raster.LineTo(float32(p.x-ox), float32(p.y-oy))
+		}
+		c.raster.ClosePath()
+	}
+	c.raster.Draw(c.img, bounds, image.NewUniform(c.state.color), image.Point{})
+}
+
+func signedArea(poly []point) float64 {
+	area := 0.0
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		area += a.x*b.y - b.x*a.y
+	}
+	return area / 2
+}
+
+func reversed(poly []point) []point {
+	out := make([]point, len(poly))
+	for i, p := range poly {
+		out[len(poly)-1-i] = p
+	}
+	return out
+}
+
+// strokePolyline outlines pts as one quad per segment with round joins, and
+// butt caps at the ends to match cairo's defaults.
+func strokePolyline(pts []point, width float64) [][]point {
+	hw := width / 2
+	var polys [][]point
+	for i := 0; i+1 < len(pts); i++ {
+		a, b := pts[i], pts[i+1]
+		dx, dy := b.x-a.x, b.y-a.y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*hw, dx/length*hw
+		polys = append(polys, []point{
+			{a.x + nx, a.y + ny},
+			{b.x + nx, b.y + ny},
+			{b.x - nx, b.y - ny},
+			{a.x - nx, a.y - ny},
+		})
+		if i > 0 {
+			polys = append(polys, circlePolygon(a, hw))
+		}
+	}
+	return polys
+}
+
+func circlePolygon(center point, radius float64) []point {
+	n := arcSegments(radius, 2*math.Pi)
+	if n < 8 {
+		n = 8
+	}
+	poly := make([]point, n)
+	for i := range poly {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		poly[i] = point{center.x + radius*math.Cos(a), center.y + radius*math.Sin(a)}
+	}
+	return poly
+}
+
+// dashPolyline splits pts into the "on" pieces of a cairo-style dash pattern.
+func dashPolyline(pts []point, dashes []float64, offset float64) [][]point {
+	total := 0.0
+	for _, d := range dashes {
+		total += d
+	}
+	if len(pts) < 2 || total <= 0 {
+		return [][]point{pts}
+	}
+
+	i, on, remain := 0, true, dashes[0]
+	offset = math.Mod(offset, total)
+	for offset > 0 {
+		if offset < remain {
+			remain -= offset
+			break
+		}
+		offset -= remain
+		i, on = (i+1)%len(dashes), !on
+		remain = dashes[i]
+	}
+
+	var out [][]point
+	var cur []point
+	if on {
+		cur = []point{pts[0]}
+	}
+	for s := 0; s+1 < len(pts); s++ {
+		a, b := pts[s], pts[s+1]
+		segLen := math.Hypot(b.x-a.x, b.y-a.y)
+		pos := 0.0
+		for segLen-pos > remain {
+			pos += remain
+			t := pos / segLen
+			p := point{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t}
+			if on {
+				out = append(out, append(cur, p))
+				cur = nil
+			} else {
+				cur = []point{p}
+			}
+			i, on = (i+1)%len(dashes), !on
+			remain = dashes[i]
+		}
+		remain -= segLen - pos
+		if on {
+			cur = append(cur, b)
+		}
+	}
+	if on && len(cur) > 1 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+func (c *imageCanvas) SetLineWidth(width float64) {
+	c.state.lineWidth = width
+}
+
+func (c *imageCanvas) SetDash(dashes []float64, offset float64) {
+	c.state.dashes = append([]float64(nil), dashes...)
+	c.state.dashOffset = offset
+}
+
+func (c *imageCanvas) SelectFont(family string, bold bool) {
+	c.state.family = family
+	c.state.bold = bold
+}
+
+func (c *imageCanvas) SetFontSize(size float64) {
+	c.state.fontSize = size
+}
+
+func (c *imageCanvas) face() font.Face {
+	key := faceKey{c.state.family, c.state.fontSize}
+	if f, ok := c.faces[key]; ok {
+		return f
+	}
+	sf, ok := embeddedFonts()[c.state.family]
+	if !ok {
+		sf = embeddedFonts()[FontInter]
+	}
+	f, err := opentype.NewFace(sf, &opentype.FaceOptions{
+		Size:    c.state.fontSize,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil
+	}
+	c.faces[key] = f
+	return f
+}
+
+func (c *imageCanvas) TextExtents(text string) TextExtents {
+	face := c.face()
+	if face == nil {
+		return TextExtents{}
+	}
+	bounds, advance := font.BoundString(face, text)
+	e := TextExtents{
+		XBearing: fixedToFloat(bounds.Min.X),
+		YBearing: fixedToFloat(bounds.Min.Y),
+		Width:    fixedToFloat(bounds.Max.X - bounds.Min.X),
+		Height:   fixedToFloat(bounds.Max.Y - bounds.Min.Y),
+		XAdvance: fixedToFloat(advance),
+	}
+	if c.state.bold && e.Width > 0 {
+		e.Width += boldOffset
+		e.XAdvance += boldOffset
+	}
+	return e
+}
+
+// ShowText renders text at the current point. Glyphs are first drawn into an
+// alpha mask so that synthetic bold does not double up translucent colors.
+func (c *imageCanvas) ShowText(text string) {
+	face := c.face()
+	if face == nil || text == "" {
+		return
+	}
+	origin := c.cur
+
+	bounds, advance := font.BoundString(face, text)
+	r := image.Rect(
+		int(math.Floor(origin.x+fixedToFloat(bounds.Min.X)))-1,
+		int(math.Floor(origin.y+fixedToFloat(bounds.Min.Y)))-1,
+		int(math.Ceil(origin.x+fixedToFloat(bounds.Max.X)+boldOffset))+1,
+		int(math.Ceil(origin.y+fixedToFloat(bounds.Max.Y)))+1,
+	)
+	mask := image.NewAlpha(r)
+
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: floatToFixed(origin)}
+	d.DrawString(text)
+	if c.state.bold {
+		d.Dot = floatToFixed(point{origin.x + boldOffset, origin.y})
+		d.DrawString(text)
+	}
+
+	draw.DrawMask(c.img, r, image.NewUniform(c.state.color), image.Point{}, mask, r.Min, draw.Over)
+
+	c.cur = point{origin.x + fixedToFloat(advance), origin.y}
+	c.hasCur = true
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+func floatToFixed(p point) fixed.Point26_6 {
+	return fixed.Point26_6{X: fixed.Int26_6(math.Round(p.x * 64)), Y: fixed.Int26_6(math.Round(p.y * 64))}
+}
+
+func (c *imageCanvas) Save() {
+	saved := c.state
+	saved.dashes = append([]float64(nil), c.state.dashes...)
+	c.stack = append(c.stack, saved)
+}
+
+func (c *imageCanvas) Restore() {
+	if len(c.stack) == 0 {
+		return
+	}
+	c.state = c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+}
+
+func (c *imageCanvas) Translate(tx, ty float64) {
+	c.state.tx += tx
+	c.state.ty += ty
+}
+
+func (c *imageCanvas) Image() image.Image {
+	return c.img
+}
+
+func (c *imageCanvas) Close() {
+	for _, f := range c.faces {
+		f.Close()
+	}
+}
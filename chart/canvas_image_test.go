@@ -0,0 +1,99 @@
+package chart
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestImageCanvasFill(t *testing.T) {
+	c := newImageCanvas(20, 20)
+	defer c.Close()
+
+	c.SetSourceRGB(1, 0, 0)
+	c.Rectangle(5, 5, 10, 10)
+	c.Fill()
+
+	img := c.Image()
+	if got := color.RGBAModel.Convert(img.At(10, 10)).(color.RGBA); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("inside pixel = %v, want opaque red", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(2, 2)).(color.RGBA); got.A != 0 {
+		t.Errorf("outside pixel = %v, want transparent", got)
+	}
+}
+
+func TestImageCanvasTranslate(t *testing.T) {
+	c := newImageCanvas(20, 20)
+	defer c.Close()
+
+	c.SetSourceRGB(0, 0, 1)
+	c.Save()
+	c.Translate(10, 10)
+	c.Rectangle(0, 0, 5, 5)
+	c.Fill()
+	c.Restore()
+
+	img := c.Image()
+	if got := color.RGBAModel.Convert(img.At(12, 12)).(color.RGBA); got.B != 255 {
+		t.Errorf("translated pixel = %v, want blue", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(2, 2)).(color.RGBA); got.A != 0 {
+		t.Errorf("untranslated pixel = %v, want transparent", got)
+	}
+}
+
+func TestImageCanvasTextExtents(t *testing.T) {
+	c := newImageCanvas(10, 10)
+	defer c.Close()
+
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(16)
+	short := c.TextExtents("HARD")
+	long := c.TextExtents("VERY HARD +")
+
+	if short.Width <= 0 || short.Height <= 0 {
+		t.Fatalf("TextExtents(HARD) = %+v, want positive size", short)
+	}
+	if long.Width <= short.Width {
+		t.Errorf("longer text should be wider: %f <= %f", long.Width, short.Width)
+	}
+	if short.YBearing >= 0 {
+		t.Errorf("YBearing = %f, want negative (above baseline)", short.YBearing)
+	}
+
+	c.SelectFont(FontBankSans, true)
+	if bold := c.TextExtents("HARD"); bold.Width <= short.Width {
+		t.Errorf("bold width %f should exceed regular width %f", bold.Width, short.Width)
+	}
+}
+
+func TestDashPolyline(t *testing.T) {
+	line := []point{{0, 0}, {30, 0}}
+
+	dashes := dashPolyline(line, []float64{8, 5}, 0)
+	// 0-8 on, 8-13 off, 13-21 on, 21-26 off, 26-30 on
+	want := [][2]float64{{0, 8}, {13, 21}, {26, 30}}
+	if len(dashes) != len(want) {
+		t.Fatalf("got %d dashes, want %d", len(dashes), len(want))
+	}
+	for i, d := range dashes {
+		start, end := d[0].x, d[len(d)-1].x
+		if math.Abs(start-want[i][0]) > 1e-9 || math.Abs(end-want[i][1]) > 1e-9 {
+			t.Errorf("dash %d = [%f, %f], want %v", i, start, end, want[i])
+		}
+	}
+
+	if solid := dashPolyline(line, nil, 0); len(solid) != 1 {
+		t.Errorf("no dash pattern should yield one piece, got %d", len(solid))
+	}
+}
+
+func TestArcSegments(t *testing.T) {
+	if n := arcSegments(20, math.Pi/2); n < 4 {
+		t.Errorf("quarter arc of radius 20 flattened to %d segments, want a smooth curve", n)
+	}
+	if n := arcSegments(0, math.Pi); n != 1 {
+		t.Errorf("degenerate arc should be one segment, got %d", n)
+	}
+}
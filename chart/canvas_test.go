@@ -0,0 +1,58 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+// useBackend switches the raster backend for the duration of a test.
+func useBackend(t *testing.T, name string) {
+	t.Helper()
+	prev := activeBackend
+	if err := SetBackend(name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { activeBackend = prev })
+}
+
+func TestSetBackend(t *testing.T) {
+	defer func(name string) { activeBackend = name }(activeBackend)
+
+	if err := SetBackend("nope"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+
+	for _, name := range Backends() {
+		if err := SetBackend(name); err != nil {
+			t.Errorf("SetBackend(%q) error: %v", name, err)
+		}
+		if got := BackendName(); got != name {
+			t.Errorf("BackendName() = %q, want %q", got, name)
+		}
+	}
+}
+
+func TestRenderChartEachBackend(t *testing.T) {
+	defer func(name string) { activeBackend = name }(activeBackend)
+
+	votes := map[string]int{"Medium": 10, "Medium +": 5, "Hard -": 3}
+	for _, name := range Backends() {
+		t.Run(name, func(t *testing.T) {
+			if err := SetBackend(name); err != nil {
+				t.Fatal(err)
+			}
+			data, err := RenderChart(votes, Options{Format: FormatPNG})
+			if err != nil {
+				t.Fatalf("RenderChart error: %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("decode PNG: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != CanvasWidth || b.Dy() != CanvasHeight {
+				t.Errorf("image size = %v, want %dx%d", b.Size(), CanvasWidth, CanvasHeight)
+			}
+		})
+	}
+}
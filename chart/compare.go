@@ -0,0 +1,177 @@
+package chart
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// comparisonBarGap separates the two bars sharing a level's slot.
+	comparisonBarGap = 3
+	// previousBarAlpha fades the bars of the earlier round.
+	previousBarAlpha = 0.4
+)
+
+// previousAverageDash is the dash pattern of the earlier round's average
+// line, in design pixels; the current round uses the usual 8-5 dash.
+var previousAverageDash = []float64{3, 4}
+
+// RenderComparison renders two rounds of votes on the same map side by side:
+// for every level a faded bar for before and a solid bar for after. The
+// window covers the levels either round would show, both averages are drawn
+// and the header reports how many tiers the average moved. The median, IQR,
+// confidence and agreement overlays are not drawn.
+func RenderComparison(before, after Tally, opts Options) ([]byte, error) {
+	if before == nil {
+		before = Tally{}
+	}
+	opts.previous = before
+	return RenderTally(after, opts)
+}
+
+// tierDelta returns how many levels the label of the average moved from
+// before to after; positive is harder.
+func (s *Scale) tierDelta(before, after float64) int {
+	from, _ := s.Index(s.AverageToLabel(before))
+	to, _ := s.Index(s.AverageToLabel(after))
+	return to - from
+}
+
+// deltaText describes the change of the average, e.g.
+// "CHANGE: +1.31 (2 TIERS HARDER)".
+func deltaText(delta float64, tiers int) string {
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+	}
+	text := "CHANGE: " + sign + formatFloat(math.Abs(delta))
+
+	n := tiers
+	if n < 0 {
+		n = -n
+	}
+	switch {
+	case tiers == 0:
+		return text + " (SAME TIER)"
+	case n == 1:
+		text += " (1 TIER"
+	default:
+		text += " (" + formatInt(n) + " TIERS"
+	}
+	if tiers > 0 {
+		return text + " HARDER)"
+	}
+	return text + " EASIER)"
+}
+
+// unionWindow returns the smallest window containing both windows.
+func unionWindow(sc *Scale, a, b Tally, markers ...string) (minIdx, maxIdx int) {
+	minA, maxA := sc.TallyWindow(a, markers...)
+	minB, maxB := sc.TallyWindow(b, markers...)
+	return min(minA, minB), max(maxA, maxB)
+}
+
+// drawComparison is drawChart for RenderComparison.
+func drawComparison(c Canvas, l layout, th *Theme, sc *Scale, before, after Tally, opts Options) {
+	setColor(c, th.Background)
+	c.Rectangle(0, 0, l.width, l.height)
+	c.Fill()
+
+	markers := opts.markers(th)
+	minIdx, maxIdx := unionWindow(sc, before, after, markerLevels(markers)...)
+	beforeBars := newBarValues(before, opts.YAxis, opts.BarLabels)
+	afterBars := newBarValues(after, opts.YAxis, opts.BarLabels)
+	maxVotes := max(axisMax(sc, beforeBars.values, minIdx, maxIdx, opts.YAxis), axisMax(sc, afterBars.values, minIdx, maxIdx, opts.YAxis))
+
+	drawYAxisLines(c, l, th, maxVotes)
+	drawComparisonBars(c, l, th, sc, beforeBars, afterBars, minIdx, maxIdx, maxVotes)
+	drawXAxisLabels(c, l, th, sc, minIdx, maxIdx)
+	drawYAxis(c, l, th, maxVotes, opts.YAxis)
+
+	prevAvg := sc.aggregate(sc.TallyStats(before), opts.Aggregation)
+	avg := sc.aggregate(sc.TallyStats(after), opts.Aggregation)
+	prevX := valueX(l, sc, prevAvg, minIdx, maxIdx)
+	avgX := valueX(l, sc, avg, minIdx, maxIdx)
+
+	prevLine := th.AverageLine
+	prevLine.A *= 0.55
+	setColor(c, prevLine)
+	c.SetLineWidth(l.px(2))
+	c.SetDash(scaleDash(l, previousAverageDash), 0)
+	c.MoveTo(prevX, l.top())
+	c.LineTo(prevX, l.bottom())
+	c.Stroke()
+	c.SetDash(nil, 0)
+
+	label := opts.Aggregation.Mode.Label()
+	header := []headerLabel{
+		{text: "BEFORE " + label + ": " + formatFloat(prevAvg) + " (" + strings.ToUpper(sc.AverageToLabel(prevAvg)) + ")", anchorX: prevX},
+		{text: "AFTER " + label + ": " + formatFloat(avg) + " (" + strings.ToUpper(sc.AverageToLabel(avg)) + ")", anchorX: avgX},
+		{text: deltaText(avg-prevAvg, sc.tierDelta(prevAvg, avg)), anchorX: l.right()},
+	}
+	for i, m := range markers {
+		drawMarkerLine(c, l, m.color, i, valueX(l, sc, sc.Midpoints[m.level], minIdx, maxIdx))
+	}
+	drawAverageLine(c, l, th, avgX, nil, false)
+	drawHeaderLabels(c, l, th, header, l.right())
+
+	swatch := fallbackBarColor
+	faded := swatch
+	faded.A = previousBarAlpha
+	drawLegend(c, l, th, append([]legendEntry{
+		{swatch: faded, line: prevLine, dash: previousAverageDash, text: "BEFORE: " + formatCount(sumTally(before)) + " VOTES"},
+		{swatch: swatch, line: th.AverageLine, dash: []float64{8, 5}, text: "AFTER: " + formatCount(sumTally(after)) + " VOTES"},
+	}, markerLegend(markers)...))
+}
+
+func sumTally(t Tally) float64 {
+	var total float64
+	for _, count := range t {
+		total += count
+	}
+	return total
+}
+
+// drawComparisonBars splits every level's slot between a faded bar for
+// before on the left and a solid bar for after on the right, each labeled
+// with its count or share.
+func drawComparisonBars(c Canvas, l layout, th *Theme, sc *Scale, before, after barValues, minIdx, maxIdx, maxVotes int) {
+	chartHeight := l.chartHeight()
+	numBars := maxIdx - minIdx + 1
+	gap := l.px(comparisonBarGap)
+	barWidth := (l.barWidth(numBars) - gap) / 2
+	radius := math.Min(l.px(BarRadius), barWidth/2)
+
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(11))
+
+	for i := minIdx; i <= maxIdx; i++ {
+		level := sc.Levels[i]
+		slotX := l.barX(i-minIdx, numBars)
+		for side, bars := range []barValues{before, after} {
+			value := bars.values[level]
+			if value == 0 {
+				continue
+			}
+			x := slotX + float64(side)*(barWidth+gap)
+			barHeight := (value / float64(maxVotes)) * chartHeight
+			y := l.top() + chartHeight - barHeight
+
+			col := th.barColor(level)
+			if side == 0 {
+				col.A *= previousBarAlpha
+			} else {
+				setColor(c, th.BarShadow)
+				drawRoundedTopRect(c, x+l.px(ShadowOffsetX), y+l.px(ShadowOffsetY), barWidth, barHeight, radius)
+				c.Fill()
+			}
+			setColor(c, col)
+			drawRoundedTopRect(c, x, y, barWidth, barHeight, radius)
+			c.Fill()
+
+			text := bars.label(level)
+			extents := c.TextExtents(text)
+			drawTextWithShadow(c, l, th, text, x+barWidth/2-extents.Width/2, y-l.px(10))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package chart
+
+import "testing"
+
+func TestDeltaText(t *testing.T) {
+	tests := []struct {
+		delta float64
+		tiers int
+		want  string
+	}{
+		{1.31, 2, "CHANGE: +1.31 (2 TIERS HARDER)"},
+		{0.4, 1, "CHANGE: +0.40 (1 TIER HARDER)"},
+		{-0.1, 0, "CHANGE: -0.10 (SAME TIER)"},
+		{0, 0, "CHANGE: +0.00 (SAME TIER)"},
+		{-0.9, -1, "CHANGE: -0.90 (1 TIER EASIER)"},
+		{-2.5, -3, "CHANGE: -2.50 (3 TIERS EASIER)"},
+	}
+	for _, tt := range tests {
+		if got := deltaText(tt.delta, tt.tiers); got != tt.want {
+			t.Errorf("deltaText(%v, %d) = %q, want %q", tt.delta, tt.tiers, got, tt.want)
+		}
+	}
+}
+
+func TestTierDelta(t *testing.T) {
+	sc := GenjiScale
+	tests := []struct {
+		before, after float64
+		want          int
+	}{
+		{sc.Midpoints["Medium"], sc.Midpoints["Medium"], 0},
+		{sc.Midpoints["Medium"], sc.Midpoints["Hard -"], 2},
+		{sc.Midpoints["Hard"], sc.Midpoints["Medium +"], -2},
+	}
+	for _, tt := range tests {
+		if got := sc.tierDelta(tt.before, tt.after); got != tt.want {
+			t.Errorf("tierDelta(%v, %v) = %d, want %d", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestUnionWindow(t *testing.T) {
+	sc := GenjiScale
+	a := Tally{"Medium": 4}
+	b := Tally{"Hard": 2}
+	minA, _ := sc.TallyWindow(a)
+	_, maxB := sc.TallyWindow(b)
+	gotMin, gotMax := unionWindow(sc, a, b)
+	if gotMin != minA || gotMax != maxB {
+		t.Errorf("unionWindow() = %d, %d, want %d, %d", gotMin, gotMax, minA, maxB)
+	}
+	if m1, m2 := unionWindow(sc, b, a); m1 != gotMin || m2 != gotMax {
+		t.Errorf("unionWindow() is not symmetric: %d, %d", m1, m2)
+	}
+}
+
+func TestRenderComparison(t *testing.T) {
+	useBackend(t, "go")
+	before := Tally{"Medium": 6, "Medium +": 4}
+	after := Tally{"Medium +": 3, "Hard -": 7, "Hard": 2.5}
+	for _, opts := range []Options{
+		{Format: FormatPNG},
+		{Format: FormatPNG, Theme: "light", Width: 400, Height: 200},
+		{Format: FormatPNG, Aggregation: Aggregation{Mode: AggregateMedian}},
+	} {
+		data, err := RenderComparison(before, after, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderComparison(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	if data, err := RenderComparison(nil, after, Options{Format: FormatPNG}); err != nil || len(data) == 0 {
+		t.Errorf("RenderComparison(nil, ...) = %d bytes, %v", len(data), err)
+	}
+}
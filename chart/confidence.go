@@ -0,0 +1,43 @@
+package chart
+
+import "math"
+
+// ConfidenceLevel is the coverage of the interval reported in
+// Stats.CILow and Stats.CIHigh.
+const ConfidenceLevel = 0.95
+
+// tCritical95 holds the two-sided 95% critical values of Student's t
+// distribution for 1 to 30 degrees of freedom.
+var tCritical95 = [...]float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tCritical returns the two-sided 95% critical value for df degrees of
+// freedom. Beyond the table it uses the first Cornish-Fisher correction to
+// the normal quantile, which is within 0.002 of the exact value.
+func tCritical(df int) float64 {
+	if df <= len(tCritical95) {
+		return tCritical95[df-1]
+	}
+	const z = 1.959964
+	return z + (z*z*z+z)/(4*float64(df))
+}
+
+// confidenceInterval returns the t interval for the mean of n votes with the
+// given population standard deviation, clamped to the extent of the scale.
+// For weighted votes n is their effective number, rounded down for the
+// degrees of freedom. Less than two votes say nothing about spread, so they
+// yield the whole scale.
+func (s *Scale) confidenceInterval(mean, stdDev, n float64) (low, high float64) {
+	lo := s.Ranges[s.Levels[0]].Lower
+	hi := s.Ranges[s.Levels[len(s.Levels)-1]].Upper
+	if n < 2 {
+		return lo, hi
+	}
+
+	sample := stdDev * math.Sqrt(n/(n-1))
+	margin := tCritical(int(n)-1) * sample / math.Sqrt(n)
+	return math.Max(lo, mean-margin), math.Min(hi, mean+margin)
+}
@@ -0,0 +1,139 @@
+package chart
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTCritical(t *testing.T) {
+	tests := []struct {
+		df   int
+		want float64
+	}{
+		{1, 12.706},
+		{10, 2.228},
+		{30, 2.042},
+		{40, 2.021},
+		{120, 1.980},
+		{100000, 1.960},
+	}
+	for _, tt := range tests {
+		if got := tCritical(tt.df); math.Abs(got-tt.want) > 0.002 {
+			t.Errorf("tCritical(%d) = %.4f, want %.3f", tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestStatsConfidenceInterval(t *testing.T) {
+	// Hard - and Hard + sit 0.58 either side of Hard, so 20 votes split
+	// between them have mean 5, sample standard deviation 0.58*sqrt(20/19)
+	// and a margin of t(19) times that over sqrt(20).
+	margin := 2.093 * 0.58 * math.Sqrt(20.0/19) / math.Sqrt(20)
+
+	tests := []struct {
+		name                string
+		votes               map[string]int
+		low, high           float64
+		lowLevel, highLevel string
+	}{
+		{
+			name:     "single vote spans the scale",
+			votes:    map[string]int{"Hard": 1},
+			low:      0,
+			high:     10,
+			lowLevel: "Easy -", highLevel: "Hell",
+		},
+		{
+			name:     "unanimous votes",
+			votes:    map[string]int{"Hard": 5},
+			low:      5,
+			high:     5,
+			lowLevel: "Hard", highLevel: "Hard",
+		},
+		{
+			name:     "symmetric split",
+			votes:    map[string]int{"Hard -": 10, "Hard +": 10},
+			low:      5 - margin,
+			high:     5 + margin,
+			lowLevel: "Hard", highLevel: "Hard",
+		},
+		{
+			name:     "clamped to the scale",
+			votes:    map[string]int{"Easy -": 1, "Hell": 1},
+			low:      0,
+			high:     10,
+			lowLevel: "Easy -", highLevel: "Hell",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := CalculateStats(tt.votes)
+			if !approx(st.CILow, tt.low) || !approx(st.CIHigh, tt.high) {
+				t.Errorf("interval = [%v, %v], want [%v, %v]", st.CILow, st.CIHigh, tt.low, tt.high)
+			}
+			if st.CILowLevel != tt.lowLevel || st.CIHighLevel != tt.highLevel {
+				t.Errorf("levels = %s to %s, want %s to %s", st.CILowLevel, st.CIHighLevel, tt.lowLevel, tt.highLevel)
+			}
+		})
+	}
+}
+
+func TestSampleStatsConfidenceInterval(t *testing.T) {
+	lo := GenjiScale.Ranges[GenjiScale.Levels[0]].Lower
+	hi := GenjiScale.Ranges[GenjiScale.Levels[len(GenjiScale.Levels)-1]].Upper
+
+	// One ballot of weight 3 is still a single voter.
+	heavy := Tally{"Hard": 3}
+	if st := GenjiScale.SampleStats(heavy, EffectiveVotes([]Ballot{{Level: "Hard", Weight: 3}})); st.CILow != lo || st.CIHigh != hi {
+		t.Errorf("single heavy ballot: interval = [%v, %v], want the whole scale [%v, %v]", st.CILow, st.CIHigh, lo, hi)
+	}
+
+	// Three light ballots are three voters, whatever their weights sum to.
+	ballots := []Ballot{{Level: "Medium", Weight: 0.4}, {Level: "Hard", Weight: 0.4}, {Level: "Hard", Weight: 0.4}}
+	light := GenjiScale.SampleStats(TallyBallots(ballots), EffectiveVotes(ballots))
+	plain := GenjiScale.Stats(map[string]int{"Medium": 1, "Hard": 2})
+	if !approx(light.CILow, plain.CILow) || !approx(light.CIHigh, plain.CIHigh) {
+		t.Errorf("light ballots: interval = [%v, %v], want [%v, %v] as for unweighted votes", light.CILow, light.CIHigh, plain.CILow, plain.CIHigh)
+	}
+
+	// Zero falls back to the summed weight.
+	if st, want := GenjiScale.SampleStats(heavy, 0), GenjiScale.TallyStats(heavy); st.CILow != want.CILow || st.CIHigh != want.CIHigh {
+		t.Errorf("n = 0: interval = [%v, %v], want [%v, %v]", st.CILow, st.CIHigh, want.CILow, want.CIHigh)
+	}
+}
+
+func TestConfidenceIntervalNarrowsWithVotes(t *testing.T) {
+	prev := math.Inf(1)
+	for _, n := range []int{2, 5, 20, 100} {
+		st := CalculateStats(map[string]int{"Medium": n, "Hard": n})
+		width := st.CIHigh - st.CILow
+		if width >= prev {
+			t.Errorf("%d votes per level: width %v, not below %v", n, width, prev)
+		}
+		if st.CILow > st.Mean || st.CIHigh < st.Mean {
+			t.Errorf("%d votes per level: [%v, %v] does not contain the mean %v", n, st.CILow, st.CIHigh, st.Mean)
+		}
+		prev = width
+	}
+}
+
+func TestConfidenceHeader(t *testing.T) {
+	st := Stats{Mean: 3.5, CILowLevel: "Medium", CIHighLevel: "Hard"}
+	tests := []struct {
+		name string
+		agg  Aggregation
+		want string
+	}{
+		{"default", Aggregation{}, "95% CI: MEDIUM TO HARD"},
+		{"mean", Aggregation{Mode: AggregateMean}, "95% CI: MEDIUM TO HARD"},
+		{"median names the mean", Aggregation{Mode: AggregateMedian}, "MEAN: 3.50, 95% CI: MEDIUM TO HARD"},
+		{"bayesian names the mean", Aggregation{Mode: AggregateBayesian, Prior: "Hard"}, "MEAN: 3.50, 95% CI: MEDIUM TO HARD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := confidenceHeader(st, tt.agg); got != tt.want {
+				t.Errorf("confidenceHeader = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package chart
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+)
 
 var DifficultyLevels = []string{
 	"Easy -", "Easy", "Easy +",
@@ -11,17 +14,9 @@ var DifficultyLevels = []string{
 	"Hell",
 }
 
-var difficultyIndexMap = func() map[string]int {
-	m := make(map[string]int, len(DifficultyLevels))
-	for i, level := range DifficultyLevels {
-		m[level] = i
-	}
-	return m
-}()
-
+// DifficultyIndex returns the position of a level on GenjiScale.
 func DifficultyIndex(name string) (int, bool) {
-	idx, ok := difficultyIndexMap[name]
-	return idx, ok
+	return GenjiScale.Index(name)
 }
 
 var DifficultyColors = map[string]string{
@@ -89,12 +84,32 @@ var DifficultyRanges = map[string]DifficultyRange{
 	"Hell":        {9.41, 10.0, true},
 }
 
-func ParseHexColor(hex string) (r, g, b uint8) {
-	if len(hex) != 7 || hex[0] != '#' {
-		return 0, 0, 0
+// ParseHexColor parses a CSS-style hex color in #RGB, #RRGGBB or #RRGGBBAA
+// form.
+func ParseHexColor(hex string) (Color, error) {
+	if len(hex) == 0 || hex[0] != '#' {
+		return Color{}, fmt.Errorf("invalid hex color %q: must start with #", hex)
+	}
+	digits := hex[1:]
+	switch len(digits) {
+	case 3:
+		digits = string([]byte{
+			digits[0], digits[0],
+			digits[1], digits[1],
+			digits[2], digits[2],
+		})
+	case 6, 8:
+	default:
+		return Color{}, fmt.Errorf("invalid hex color %q: want #RGB, #RRGGBB or #RRGGBBAA", hex)
+	}
+
+	channels := [4]float64{1, 1, 1, 1}
+	for i := 0; i < len(digits)/2; i++ {
+		v, err := strconv.ParseUint(digits[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid hex color %q: bad digits %q", hex, digits[2*i:2*i+2])
+		}
+		channels[i] = float64(v) / 255
 	}
-	rVal, _ := strconv.ParseUint(hex[1:3], 16, 8)
-	gVal, _ := strconv.ParseUint(hex[3:5], 16, 8)
-	bVal, _ := strconv.ParseUint(hex[5:7], 16, 8)
-	return uint8(rVal), uint8(gVal), uint8(bVal)
+	return Color{channels[0], channels[1], channels[2], channels[3]}, nil
 }
@@ -52,18 +52,33 @@ func TestDifficultyIndexInvalid(t *testing.T) {
 
 func TestParseHexColor(t *testing.T) {
 	tests := []struct {
-		hex     string
-		r, g, b uint8
+		hex        string
+		r, g, b, a uint8
 	}{
-		{"#66ff66", 0x66, 0xff, 0x66},
-		{"#990000", 0x99, 0x00, 0x00},
-		{"#ffb300", 0xff, 0xb3, 0x00},
+		{"#66ff66", 0x66, 0xff, 0x66, 0xff},
+		{"#990000", 0x99, 0x00, 0x00, 0xff},
+		{"#ffb300", 0xff, 0xb3, 0x00, 0xff},
+		{"#FFB300", 0xff, 0xb3, 0x00, 0xff},
+		{"#f80", 0xff, 0x88, 0x00, 0xff},
+		{"#ffb30080", 0xff, 0xb3, 0x00, 0x80},
 	}
 	for _, tt := range tests {
-		r, g, b := ParseHexColor(tt.hex)
-		if r != tt.r || g != tt.g || b != tt.b {
-			t.Errorf("ParseHexColor(%q) = (%d, %d, %d), want (%d, %d, %d)",
-				tt.hex, r, g, b, tt.r, tt.g, tt.b)
+		col, err := ParseHexColor(tt.hex)
+		if err != nil {
+			t.Errorf("ParseHexColor(%q) error: %v", tt.hex, err)
+			continue
+		}
+		want := Color{float64(tt.r) / 255, float64(tt.g) / 255, float64(tt.b) / 255, float64(tt.a) / 255}
+		if col != want {
+			t.Errorf("ParseHexColor(%q) = %v, want %v", tt.hex, col, want)
+		}
+	}
+}
+
+func TestParseHexColorInvalid(t *testing.T) {
+	for _, hex := range []string{"", "#", "66ff66", "#66ff6", "#66ff66f", "#gg0000", "#12345", "#+12"} {
+		if col, err := ParseHexColor(hex); err == nil {
+			t.Errorf("ParseHexColor(%q) = %v, want error", hex, col)
 		}
 	}
 }
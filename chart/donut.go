@@ -0,0 +1,261 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ChartType selects how the vote distribution is drawn.
+type ChartType string
+
+const (
+	// ChartBars draws one bar per level.
+	ChartBars ChartType = "bar"
+	// ChartDonut draws each voted level's share as a segment of a ring,
+	// with the average in the hole; it suits small, square embeds.
+	ChartDonut ChartType = "donut"
+)
+
+var chartTypes = map[ChartType]bool{
+	ChartBars:  true,
+	ChartDonut: true,
+}
+
+// LookupChartType returns the named chart type. An empty name selects
+// ChartBars.
+func LookupChartType(name string) (ChartType, error) {
+	if name == "" {
+		return ChartBars, nil
+	}
+	t := ChartType(name)
+	if !chartTypes[t] {
+		return "", fmt.Errorf("unknown chart type: %s (available: %v)", name, ChartTypeNames())
+	}
+	return t, nil
+}
+
+func ChartTypeNames() []string {
+	names := make([]string, 0, len(chartTypes))
+	for t := range chartTypes {
+		names = append(names, string(t))
+	}
+	sort.Strings(names)
+	return names
+}
+
+const (
+	// donutHole is the radius of the hole as a share of the outer radius.
+	donutHole = 0.6
+	// donutSmallSlice is the share of the votes below which a segment's
+	// label is connected to it by a leader line.
+	donutSmallSlice = 0.06
+	// donutLabelOffset is the distance of the labels from the ring.
+	donutLabelOffset = 14
+	donutLabelHeight = 17
+)
+
+// donutLayout rescales l by the shorter side of the canvas only, since a
+// donut needs no more width than height; square embeds then keep the text
+// size of the default chart.
+func donutLayout(l layout) layout {
+	l.unit = math.Min(l.width, l.height) / CanvasHeight
+	return l
+}
+
+// donutRadius returns the outer radius of the ring between the title block
+// and the footer, leaving room for labels up to labelWidth wide on either side.
+func donutRadius(l layout, labelWidth float64) float64 {
+	r := math.Min((l.height-l.title-l.footer)/2-l.px(36), l.width/2-labelWidth-l.px(donutLabelOffset+24))
+	return math.Max(r, l.px(20))
+}
+
+// donutSlice is the segment of one voted level.
+type donutSlice struct {
+	level      string
+	share      float64
+	start, end float64
+}
+
+func (s donutSlice) mid() float64 { return (s.start + s.end) / 2 }
+
+func (s donutSlice) label() string {
+	return strings.ToUpper(s.level) + " " + percentText(s.share)
+}
+
+func selectDonutLabelFont(c Canvas, l layout) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(13))
+}
+
+// donutSlices splits the ring between the voted levels in scale order,
+// clockwise from the top.
+func donutSlices(st Stats) []donutSlice {
+	var slices []donutSlice
+	angle := -math.Pi / 2
+	for _, sh := range st.Shares {
+		if sh.Votes <= 0 {
+			continue
+		}
+		share := sh.Votes / st.TotalVotes
+		slices = append(slices, donutSlice{level: sh.Level, share: share, start: angle, end: angle + share*2*math.Pi})
+		angle += share * 2 * math.Pi
+	}
+	return slices
+}
+
+// percentText formats a share of the votes as a whole percentage.
+func percentText(share float64) string {
+	pct := share * 100
+	if pct > 0 && pct < 0.5 {
+		return "<1%"
+	}
+	return formatInt(int(math.Round(pct))) + "%"
+}
+
+// spreadLabels moves label centers apart so neighbors are at least gap
+// apart and all stay between lo and hi, keeping their order. It returns the
+// new centers in input order.
+func spreadLabels(ys []float64, gap, lo, hi float64) []float64 {
+	order := make([]int, len(ys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ys[order[a]] < ys[order[b]] })
+
+	out := make([]float64, len(ys))
+	prev := math.Inf(-1)
+	for _, i := range order {
+		out[i] = math.Max(math.Max(ys[i], prev+gap), lo)
+		prev = out[i]
+	}
+	next := math.Inf(1)
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		out[i] = math.Min(math.Min(out[i], next-gap), hi)
+		next = out[i]
+	}
+	return out
+}
+
+// donut reports whether o renders as a donut: grouped, comparison and
+// timeline charts ignore Type.
+func (o Options) donut() bool {
+	return o.Type == ChartDonut && len(o.groups) == 0 && o.previous == nil && o.timeline == nil
+}
+
+// drawDonut is drawChart for ChartDonut; l must come from donutLayout.
+func drawDonut(c Canvas, l layout, th *Theme, sc *Scale, votes Tally, opts Options) {
+	setColor(c, th.Background)
+	c.Rectangle(0, 0, l.width, l.height)
+	c.Fill()
+
+	st := sc.TallyStats(votes)
+	if st.TotalVotes == 0 {
+		return
+	}
+	slices := donutSlices(st)
+
+	selectDonutLabelFont(c, l)
+	var labelWidth float64
+	for _, s := range slices {
+		labelWidth = math.Max(labelWidth, c.TextExtents(s.label()).Width)
+	}
+	cx, cy := l.width/2, l.title+(l.height-l.title-l.footer)/2
+	r := donutRadius(l, labelWidth)
+
+	setColor(c, th.BarShadow)
+	c.Arc(cx+l.px(ShadowOffsetX), cy+l.px(ShadowOffsetY), r, 0, 2*math.Pi)
+	c.Fill()
+
+	for _, s := range slices {
+		setColor(c, th.barColor(s.level))
+		c.MoveTo(cx, cy)
+		c.Arc(cx, cy, r, s.start, s.end)
+		c.ClosePath()
+		c.Fill()
+	}
+	if len(slices) > 1 {
+		setColor(c, th.Background)
+		c.SetLineWidth(l.px(2.5))
+		for _, s := range slices {
+			c.MoveTo(cx, cy)
+			c.LineTo(cx+r*math.Cos(s.start), cy+r*math.Sin(s.start))
+			c.Stroke()
+		}
+	}
+	setColor(c, th.Background)
+	c.Arc(cx, cy, r*donutHole, 0, 2*math.Pi)
+	c.Fill()
+
+	drawDonutLabels(c, l, th, slices, cx, cy, r)
+	drawDonutCenter(c, l, th, sc, sc.aggregate(st, opts.Aggregation), opts.Aggregation.Mode.Label(), cx, cy)
+}
+
+// drawDonutLabels names every segment with its level and share, on the
+// side of the ring the segment is on. Labels are spread apart where they
+// would overlap, and the labels of small or displaced segments are joined
+// to them by a leader line.
+func drawDonutLabels(c Canvas, l layout, th *Theme, slices []donutSlice, cx, cy, r float64) {
+	selectDonutLabelFont(c, l)
+
+	labelR := r + l.px(donutLabelOffset)
+	for _, right := range []bool{true, false} {
+		var side []donutSlice
+		var ys []float64
+		for _, s := range slices {
+			if (math.Cos(s.mid()) >= 0) == right {
+				side = append(side, s)
+				ys = append(ys, cy+labelR*math.Sin(s.mid()))
+			}
+		}
+		placed := spreadLabels(ys, l.px(donutLabelHeight), l.title+l.px(donutLabelHeight), l.height-l.footer-l.px(donutLabelHeight)/2)
+
+		dir := 1.0
+		if !right {
+			dir = -1
+		}
+		for i, s := range side {
+			a := s.mid()
+			x, y := cx+labelR*math.Cos(a), placed[i]
+			text := s.label()
+			extents := c.TextExtents(text)
+
+			textX := x + dir*l.px(4)
+			if s.share < donutSmallSlice || math.Abs(y-ys[i]) > l.px(2) {
+				setColor(c, th.Text)
+				c.SetLineWidth(l.px(1))
+				c.MoveTo(cx+(r+l.px(2))*math.Cos(a), cy+(r+l.px(2))*math.Sin(a))
+				c.LineTo(x, y)
+				c.LineTo(x+dir*l.px(10), y)
+				c.Stroke()
+				textX = x + dir*l.px(14)
+			}
+			if !right {
+				textX -= extents.Width
+			}
+			drawTextWithShadow(c, l, th, text, textX, y-extents.YBearing-extents.Height/2)
+		}
+	}
+}
+
+// drawDonutCenter writes the average and its level in the hole.
+func drawDonutCenter(c Canvas, l layout, th *Theme, sc *Scale, avg float64, label string, cx, cy float64) {
+	lines := []struct {
+		text     string
+		size     float64
+		bold     bool
+		baseline float64
+	}{
+		{label, 12, true, -22},
+		{formatFloat(avg), 32, true, 12},
+		{strings.ToUpper(sc.AverageToLabel(avg)), 15, false, 34},
+	}
+	for _, line := range lines {
+		c.SelectFont(FontBankSans, line.bold)
+		c.SetFontSize(l.px(line.size))
+		extents := c.TextExtents(line.text)
+		drawTextWithShadow(c, l, th, line.text, cx-extents.Width/2, cy+l.px(line.baseline))
+	}
+}
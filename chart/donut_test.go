@@ -0,0 +1,109 @@
+package chart
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLookupChartType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    ChartType
+		wantErr bool
+	}{
+		{"", ChartBars, false},
+		{"bar", ChartBars, false},
+		{"donut", ChartDonut, false},
+		{"pie", "", true},
+	}
+	for _, tt := range tests {
+		got, err := LookupChartType(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("LookupChartType(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDonutSlices(t *testing.T) {
+	st := GenjiScale.TallyStats(Tally{"Hard": 3, "Easy": 1})
+	slices := donutSlices(st)
+	if len(slices) != 2 {
+		t.Fatalf("donutSlices() returned %d slices, want 2", len(slices))
+	}
+	if slices[0].level != "Easy" || slices[1].level != "Hard" {
+		t.Errorf("slices are %s, %s; want scale order", slices[0].level, slices[1].level)
+	}
+	if slices[0].start != -math.Pi/2 || math.Abs(slices[1].end-1.5*math.Pi) > 1e-9 {
+		t.Errorf("ring runs %v..%v, want a full turn from the top", slices[0].start, slices[1].end)
+	}
+	if slices[0].end != slices[1].start {
+		t.Errorf("slices leave a gap: %v, %v", slices[0].end, slices[1].start)
+	}
+	if slices[1].share != 0.75 {
+		t.Errorf("Hard share = %v, want 0.75", slices[1].share)
+	}
+}
+
+func TestPercentText(t *testing.T) {
+	tests := []struct {
+		share float64
+		want  string
+	}{
+		{1, "100%"},
+		{0.125, "13%"},
+		{0.004, "<1%"},
+		{0.006, "1%"},
+	}
+	for _, tt := range tests {
+		if got := percentText(tt.share); got != tt.want {
+			t.Errorf("percentText(%v) = %q, want %q", tt.share, got, tt.want)
+		}
+	}
+}
+
+func TestSpreadLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		ys     []float64
+		lo, hi float64
+		want   []float64
+	}{
+		{"apart", []float64{10, 50, 90}, 0, 100, []float64{10, 50, 90}},
+		{"pushed down", []float64{50, 52, 54}, 0, 100, []float64{50, 60, 70}},
+		{"input order kept", []float64{54, 50, 52}, 0, 100, []float64{70, 50, 60}},
+		{"held above hi", []float64{88, 90, 92}, 0, 95, []float64{75, 85, 95}},
+		{"held below lo", []float64{-5, 0}, 5, 100, []float64{5, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spreadLabels(tt.ys, 10, tt.lo, tt.hi); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("spreadLabels(%v) = %v, want %v", tt.ys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderChartDonut(t *testing.T) {
+	useBackend(t, "go")
+	for _, votes := range []map[string]int{
+		{"Easy": 3, "Hard": 1, "Extreme": 3},
+		{"Hard": 5},
+		{"Easy -": 1, "Easy": 1, "Hard": 40, "Hell": 1},
+	} {
+		for _, opts := range []Options{
+			{Format: FormatPNG, Type: ChartDonut},
+			{Format: FormatPNG, Type: ChartDonut, Theme: "light", Width: 400, Height: 400},
+			{Format: FormatPNG, Type: ChartDonut, Width: 200, Height: 100},
+		} {
+			data, err := RenderChart(votes, opts)
+			if err != nil || len(data) == 0 {
+				t.Errorf("RenderChart(%v, %+v) = %d bytes, %v", votes, opts, len(data), err)
+			}
+		}
+	}
+
+	if _, err := RenderChart(map[string]int{"Hard": 1}, Options{Format: FormatPNG, Type: "pie"}); err == nil {
+		t.Error("RenderChart with an unknown chart type succeeded")
+	}
+}
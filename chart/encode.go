@@ -0,0 +1,99 @@
+package chart
+
+import (
+	"image"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Format identifies an output encoding for a rendered chart.
+type Format string
+
+const (
+	FormatWebP Format = "webp"
+	FormatPNG  Format = "png"
+	FormatSVG  Format = "svg"
+)
+
+// DefaultFormat is used when the caller expresses no preference: WebP when
+// its cgo encoder is compiled in, PNG otherwise.
+func DefaultFormat() Format {
+	if _, ok := encoders[FormatWebP]; ok {
+		return FormatWebP
+	}
+	return FormatPNG
+}
+
+// Encoder writes a rendered raster image in a specific format.
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, img image.Image) error
+}
+
+var encoders = map[Format]Encoder{}
+
+// vectorRenderers draw formats that bypass the raster canvas entirely.
+var vectorRenderers = map[Format]func(votes Tally, l layout, th *Theme, sc *Scale, opts Options) ([]byte, error){}
+
+var contentTypes = map[Format]string{}
+
+// formatOrder keeps Formats in registration order.
+var formatOrder []Format
+
+func init() {
+	RegisterEncoder(FormatPNG, pngEncoder{})
+}
+
+// RegisterEncoder makes a format available to RenderChart, replacing any
+// encoder previously registered under the same name.
+func RegisterEncoder(format Format, enc Encoder) {
+	registerFormat(format, enc.ContentType())
+	encoders[format] = enc
+}
+
+func registerFormat(format Format, contentType string) {
+	if _, ok := contentTypes[format]; !ok {
+		formatOrder = append(formatOrder, format)
+	}
+	contentTypes[format] = contentType
+}
+
+// EncoderFor returns the raster encoder registered for format. Vector
+// formats such as SVG have no encoder.
+func EncoderFor(format Format) (Encoder, bool) {
+	enc, ok := encoders[format]
+	return enc, ok
+}
+
+// Formats lists every registered format.
+func Formats() []Format {
+	out := make([]Format, len(formatOrder))
+	copy(out, formatOrder)
+	return out
+}
+
+// ContentType returns the media type served for f, or "" if f is not registered.
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// ParseFormat resolves a format name ("png") or media type ("image/png")
+// to a registered format.
+func ParseFormat(s string) (Format, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, f := range formatOrder {
+		if s == string(f) || s == f.ContentType() {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+type pngEncoder struct{}
+
+func (pngEncoder) ContentType() string { return "image/png" }
+
+func (pngEncoder) Encode(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
@@ -0,0 +1,64 @@
+package chart
+
+import "testing"
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in     string
+		format Format
+		ok     bool
+	}{
+		{"webp", FormatWebP, true},
+		{"PNG", FormatPNG, true},
+		{"image/png", FormatPNG, true},
+		{"svg", FormatSVG, true},
+		{"image/svg+xml", FormatSVG, true},
+		{" image/webp ", FormatWebP, true},
+		{"gif", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if tt.ok {
+				requireFormat(t, tt.format)
+			}
+			f, ok := ParseFormat(tt.in)
+			if f != tt.format || ok != tt.ok {
+				t.Errorf("ParseFormat(%q) = (%q, %v), want (%q, %v)", tt.in, f, ok, tt.format, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFormatContentType(t *testing.T) {
+	tests := []struct {
+		format      Format
+		contentType string
+	}{
+		{FormatWebP, "image/webp"},
+		{FormatPNG, "image/png"},
+		{FormatSVG, "image/svg+xml"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			requireFormat(t, tt.format)
+			if got := tt.format.ContentType(); got != tt.contentType {
+				t.Errorf("%s.ContentType() = %q, want %q", tt.format, got, tt.contentType)
+			}
+		})
+	}
+
+	if got := Format("gif").ContentType(); got != "" {
+		t.Errorf("unregistered ContentType() = %q, want empty", got)
+	}
+}
+
+func TestDefaultFormat(t *testing.T) {
+	want := FormatPNG
+	if FormatWebP.ContentType() != "" {
+		want = FormatWebP
+	}
+	if got := DefaultFormat(); got != want {
+		t.Errorf("DefaultFormat() = %q, want %q", got, want)
+	}
+}
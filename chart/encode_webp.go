@@ -0,0 +1,29 @@
+//go:build !purego
+
+package chart
+
+import (
+	"image"
+	"io"
+
+	"github.com/kolesa-team/go-webp/encoder"
+	"github.com/kolesa-team/go-webp/webp"
+)
+
+func init() {
+	opts, err := encoder.NewLossyEncoderOptions(encoder.PresetDefault, 85)
+	if err != nil {
+		panic("failed to create webp encoder options: " + err.Error())
+	}
+	RegisterEncoder(FormatWebP, webpEncoder{opts: opts})
+}
+
+type webpEncoder struct {
+	opts *encoder.Options
+}
+
+func (webpEncoder) ContentType() string { return "image/webp" }
+
+func (e webpEncoder) Encode(w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, e.opts)
+}
@@ -0,0 +1,234 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Group is the part of the votes cast by one caller-defined group of
+// voters, such as verified playtesters.
+type Group struct {
+	Name  string
+	Votes Tally
+}
+
+// MaxGroups is the number of distinct group colors in a theme.
+const MaxGroups = 6
+
+// ValidateGroups checks that groups can be drawn: at least one and at most
+// MaxGroups groups, each with a unique, non-empty name.
+func ValidateGroups(groups []Group) error {
+	if len(groups) == 0 {
+		return errors.New("no groups provided")
+	}
+	if len(groups) > MaxGroups {
+		return fmt.Errorf("too many groups: %d (max %d)", len(groups), MaxGroups)
+	}
+	seen := make(map[string]bool, len(groups))
+	for i, g := range groups {
+		if g.Name == "" {
+			return fmt.Errorf("groups[%d]: missing name", i)
+		}
+		if seen[g.Name] {
+			return fmt.Errorf("duplicate group: %s", g.Name)
+		}
+		seen[g.Name] = true
+	}
+	return nil
+}
+
+// RenderGroups renders the combined votes of groups with each bar split
+// into one stacked segment per group, bottom to top in the given order. Each
+// group also gets its own average line and an entry in a legend below the
+// axis. Patterns are not drawn on stacked bars.
+func RenderGroups(groups []Group, opts Options) ([]byte, error) {
+	if err := ValidateGroups(groups); err != nil {
+		return nil, err
+	}
+	opts.groups = groups
+	return RenderTally(SumGroups(groups), opts)
+}
+
+// SumGroups combines the votes of all groups into one tally.
+func SumGroups(groups []Group) Tally {
+	t := make(Tally)
+	for _, g := range groups {
+		for level, count := range g.Votes {
+			t[level] += count
+		}
+	}
+	return t
+}
+
+// groupDashes gives each group's average line a dash pattern, in design
+// pixels, distinct from the overall average's 8-5 dash.
+var groupDashes = [][]float64{
+	{2, 4},
+	{14, 5},
+	{10, 4, 2, 4},
+	{4, 4},
+	{18, 4, 2, 4, 2, 4},
+	{1, 7},
+}
+
+func groupColor(th *Theme, i int) Color {
+	return th.GroupColors[i%len(th.GroupColors)]
+}
+
+// drawStackedBars is drawBars for grouped votes: every bar is cut into one
+// segment per group, sharing the bar's rounded top.
+func drawStackedBars(c Canvas, l layout, th *Theme, sc *Scale, groups []Group, votes Tally, minIdx, maxIdx, maxVotes int) {
+	chartHeight := l.chartHeight()
+	numBars := maxIdx - minIdx + 1
+	barWidth := l.barWidth(numBars)
+	radius := l.px(BarRadius)
+
+	for i := minIdx; i <= maxIdx; i++ {
+		level := sc.Levels[i]
+		total := votes[level]
+		if total == 0 {
+			continue
+		}
+
+		x := l.barX(i-minIdx, numBars)
+		barHeight := (total / float64(maxVotes)) * chartHeight
+		y := l.top() + chartHeight - barHeight
+
+		setColor(c, th.BarShadow)
+		drawRoundedTopRect(c, x+l.px(ShadowOffsetX), y+l.px(ShadowOffsetY), barWidth, barHeight, radius)
+		c.Fill()
+
+		var cum float64
+		for gi, g := range groups {
+			count := g.Votes[level]
+			if count == 0 {
+				continue
+			}
+			y1 := l.bottom() - (cum/float64(maxVotes))*chartHeight
+			cum += count
+			y0 := l.bottom() - (cum/float64(maxVotes))*chartHeight
+
+			setColor(c, groupColor(th, gi))
+			drawBarSlice(c, x, y, barWidth, barHeight, radius, y0, y1)
+			c.Fill()
+		}
+	}
+}
+
+// drawBarSlice traces the part of a drawRoundedTopRect bar that lies
+// between the horizontal lines y0 and y1 (y0 < y1), following the bar's
+// rounded corners where the slice reaches them.
+func drawBarSlice(c Canvas, x, y, w, h, r, y0, y1 float64) {
+	if h < r {
+		r = h
+	}
+	if r < 0 {
+		r = 0
+	}
+	y0 = math.Max(y0, y)
+	y1 = math.Min(y1, y+h)
+	// Above arcBottom the sides curve in along the corner arcs.
+	arcBottom := y + r
+	rise := func(yy float64) float64 { return math.Asin((arcBottom - yy) / r) }
+	inset := func(yy float64) float64 {
+		if yy >= arcBottom {
+			return 0
+		}
+		return r - r*math.Cos(rise(yy))
+	}
+
+	c.MoveTo(x+inset(y1), y1)
+	if y0 >= arcBottom {
+		c.LineTo(x, y0)
+		c.LineTo(x+w, y0)
+		c.LineTo(x+w, y1)
+		c.ClosePath()
+		return
+	}
+
+	c.LineTo(x+inset(y1), math.Min(y1, arcBottom))
+	c.Arc(x+r, arcBottom, r, math.Pi+rise(math.Min(y1, arcBottom)), math.Pi+rise(y0))
+	c.LineTo(x+w-inset(y0), y0)
+	c.Arc(x+w-r, arcBottom, r, 2*math.Pi-rise(y0), 2*math.Pi-rise(math.Min(y1, arcBottom)))
+	c.LineTo(x+w-inset(y1), y1)
+	c.ClosePath()
+}
+
+// groupAverage is a group's average as drawn on the chart.
+type groupAverage struct {
+	value float64
+	level string
+	x     float64
+}
+
+func groupAverages(l layout, sc *Scale, groups []Group, agg Aggregation, minIdx, maxIdx int) []groupAverage {
+	avgs := make([]groupAverage, len(groups))
+	for i, g := range groups {
+		v := sc.aggregate(sc.TallyStats(g.Votes), agg)
+		avgs[i] = groupAverage{value: v, level: sc.AverageToLabel(v), x: valueX(l, sc, v, minIdx, maxIdx)}
+	}
+	return avgs
+}
+
+// drawGroupAverageLines draws each group's average in the group's color and
+// dash pattern, edged in the background color so a line stays visible over
+// its own group's segments. Groups without votes have no average and are
+// skipped.
+func drawGroupAverageLines(c Canvas, l layout, th *Theme, groups []Group, avgs []groupAverage) {
+	for i, g := range groups {
+		if !hasVotes(g.Votes) {
+			continue
+		}
+		c.SetDash(groupDash(l, i), 0)
+		for _, pass := range []struct {
+			col   Color
+			width float64
+		}{{th.Background, 4}, {groupColor(th, i), 2}} {
+			setColor(c, pass.col)
+			c.SetLineWidth(l.px(pass.width))
+			c.MoveTo(avgs[i].x, l.top())
+			c.LineTo(avgs[i].x, l.bottom())
+			c.Stroke()
+		}
+	}
+	c.SetDash(nil, 0)
+}
+
+func groupDash(l layout, i int) []float64 {
+	return scaleDash(l, groupDashes[i%len(groupDashes)])
+}
+
+// scaleDash converts a dash pattern from design pixels to output pixels.
+func scaleDash(l layout, pattern []float64) []float64 {
+	dashes := make([]float64, len(pattern))
+	for j, d := range pattern {
+		dashes[j] = l.px(d)
+	}
+	return dashes
+}
+
+func hasVotes(t Tally) bool {
+	for _, count := range t {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// groupLegend lists each group's segment color, average line and
+// average.
+func groupLegend(th *Theme, groups []Group, avgs []groupAverage) []legendEntry {
+	entries := make([]legendEntry, len(groups))
+	for i, g := range groups {
+		text := strings.ToUpper(g.Name)
+		if hasVotes(g.Votes) {
+			text += ": " + formatFloat(avgs[i].value) + " (" + strings.ToUpper(avgs[i].level) + ")"
+		}
+		col := groupColor(th, i)
+		entries[i] = legendEntry{swatch: col, line: col, dash: groupDashes[i%len(groupDashes)], text: text}
+	}
+	return entries
+}
@@ -0,0 +1,63 @@
+package chart
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateGroups(t *testing.T) {
+	votes := Tally{"Hard": 1}
+	tests := []struct {
+		name    string
+		groups  []Group
+		wantErr bool
+	}{
+		{"one group", []Group{{Name: "verified", Votes: votes}}, false},
+		{"two groups", []Group{{Name: "verified", Votes: votes}, {Name: "community", Votes: votes}}, false},
+		{"none", nil, true},
+		{"missing name", []Group{{Votes: votes}}, true},
+		{"duplicate name", []Group{{Name: "a", Votes: votes}, {Name: "a", Votes: votes}}, true},
+		{"too many", make([]Group, MaxGroups+1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateGroups(tt.groups); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSumGroups(t *testing.T) {
+	got := SumGroups([]Group{
+		{Name: "verified", Votes: Tally{"Hard": 2, "Hard +": 1}},
+		{Name: "community", Votes: Tally{"Hard": 1.5, "Medium": 4}},
+	})
+	want := Tally{"Hard": 3.5, "Hard +": 1, "Medium": 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SumGroups() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderGroups(t *testing.T) {
+	useBackend(t, "go")
+	groups := []Group{
+		{Name: "verified", Votes: Tally{"Hard -": 6, "Hard": 4}},
+		{Name: "community", Votes: Tally{"Medium": 5, "Hard -": 3}},
+		{Name: "new", Votes: Tally{}},
+	}
+	for _, opts := range []Options{
+		{Format: FormatPNG},
+		{Format: FormatPNG, Theme: "light", Width: 400, Height: 200, ShowMedian: true},
+		{Format: FormatPNG, Aggregation: Aggregation{Mode: AggregateTrimmed}},
+	} {
+		data, err := RenderGroups(groups, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderGroups(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	if _, err := RenderGroups(nil, Options{Format: FormatPNG}); err == nil {
+		t.Error("RenderGroups(nil) succeeded, want an error")
+	}
+}
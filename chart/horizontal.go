@@ -0,0 +1,327 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Orientation selects which way the bars of a chart run.
+type Orientation string
+
+const (
+	// OrientationVertical draws upright bars with the levels along the x
+	// axis.
+	OrientationVertical Orientation = "vertical"
+	// OrientationHorizontal draws bars running right from the levels listed
+	// down the left axis, which leaves room for long level names.
+	OrientationHorizontal Orientation = "horizontal"
+)
+
+var orientations = map[Orientation]bool{
+	OrientationVertical:   true,
+	OrientationHorizontal: true,
+}
+
+// LookupOrientation returns the named orientation. An empty name selects
+// OrientationVertical.
+func LookupOrientation(name string) (Orientation, error) {
+	if name == "" {
+		return OrientationVertical, nil
+	}
+	o := Orientation(name)
+	if !orientations[o] {
+		return "", fmt.Errorf("unknown orientation: %s (available: %v)", name, OrientationNames())
+	}
+	return o, nil
+}
+
+func OrientationNames() []string {
+	names := make([]string, 0, len(orientations))
+	for o := range orientations {
+		names = append(names, string(o))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hplot is the plot area of a horizontal chart. Levels run from the top
+// down and counts from left to right; the left edge moves right as far as
+// the longest level label needs.
+type hplot struct {
+	left, right, top, bottom float64
+	numBars                  int
+	gap                      float64
+}
+
+const (
+	// hLabelGap separates the level labels from the plot.
+	hLabelGap = 12
+	// hMaxGapShare caps the gap between bars as a share of a bar's slot, so
+	// the bars stay visible when every level is shown.
+	hMaxGapShare = 0.3
+)
+
+func newHPlot(c Canvas, l layout, sc *Scale, minIdx, maxIdx int) hplot {
+	selectLevelFont(c, l)
+	var labelWidth float64
+	for i := minIdx; i <= maxIdx; i++ {
+		labelWidth = math.Max(labelWidth, c.TextExtents(strings.ToUpper(sc.Levels[i])).Width)
+	}
+	p := hplot{
+		left:    math.Max(l.left(), l.px(20)+labelWidth+l.px(hLabelGap)),
+		right:   l.right(),
+		top:     l.top(),
+		bottom:  l.bottom(),
+		numBars: maxIdx - minIdx + 1,
+	}
+	p.gap = math.Min(l.px(BarGap), hMaxGapShare*p.height()/float64(p.numBars))
+	return p
+}
+
+func (p hplot) width() float64  { return p.right - p.left }
+func (p hplot) height() float64 { return p.bottom - p.top }
+
+func (p hplot) barHeight() float64 {
+	return (p.height() - float64(p.numBars-1)*p.gap) / float64(p.numBars)
+}
+
+// barY returns the top edge of the bar at position i (0-based within the
+// visible window).
+func (p hplot) barY(i int) float64 {
+	return p.top + float64(i)*(p.barHeight()+p.gap)
+}
+
+// valueY is valueX for the vertical value axis of a horizontal chart.
+func (p hplot) valueY(sc *Scale, v float64, minIdx, maxIdx int) float64 {
+	minValue := sc.Ranges[sc.Levels[minIdx]].Lower
+	maxValue := sc.Ranges[sc.Levels[maxIdx]].Upper
+	return p.top + (v-minValue)/(maxValue-minValue)*p.height()
+}
+
+func selectLevelFont(c Canvas, l layout) {
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(16))
+}
+
+// drawHorizontalChart is drawChart for OrientationHorizontal.
+func drawHorizontalChart(c Canvas, l layout, th *Theme, sc *Scale, votes Tally, opts Options) {
+	setColor(c, th.Background)
+	c.Rectangle(0, 0, l.width, l.height)
+	c.Fill()
+
+	st := sc.SampleStats(votes, opts.SampleSize)
+	markers := opts.markers(th)
+	minIdx, maxIdx := sc.TallyWindow(votes, markerLevels(markers)...)
+	bars := newBarValues(votes, opts.YAxis, opts.BarLabels)
+	maxVotes := axisMax(sc, bars.values, minIdx, maxIdx, opts.YAxis)
+	p := newHPlot(c, l, sc, minIdx, maxIdx)
+	valueY := func(v float64) float64 { return p.valueY(sc, v, minIdx, maxIdx) }
+
+	drawHCountLines(c, l, th, p, maxVotes)
+	if opts.ShowIQR {
+		drawHBand(c, l, p, th.IQRBand, valueY(st.Q1), valueY(st.Q3))
+	}
+	drawHBars(c, l, th, sc, p, bars.values, minIdx, maxIdx, maxVotes, opts.Patterns)
+	drawLevelLabels(c, l, th, sc, p, minIdx, maxIdx)
+	drawCountAxis(c, l, th, p, maxVotes, opts.YAxis)
+	drawHVoteCounts(c, l, th, sc, p, bars, minIdx, maxIdx, maxVotes)
+
+	avg := sc.aggregate(st, opts.Aggregation)
+	header := []headerLabel{
+		{text: opts.Aggregation.Mode.Label() + ": " + formatFloat(avg) + " (" + strings.ToUpper(sc.AverageToLabel(avg)) + ")", anchorX: p.left},
+	}
+	if opts.ShowMedian {
+		drawHMedianLine(c, l, th, p, valueY(st.Median))
+		header = append(header, headerLabel{text: "MEDIAN: " + formatFloat(st.Median) + " (" + strings.ToUpper(st.MedianLevel) + ")", anchorX: p.left})
+	}
+	if opts.ShowConfidence {
+		drawHBand(c, l, p, th.ConfidenceBand, valueY(st.CILow), valueY(st.CIHigh))
+		header = append(header, headerLabel{text: confidenceHeader(st, opts.Aggregation), anchorX: p.left})
+	}
+	inValley := sc.valleyAt(st, avg)
+	if inValley {
+		header = append(header, splitVoteWarning(th, st.Peaks, p.left))
+	}
+	for i, m := range markers {
+		drawHMarkerLine(c, l, p, m.color, i, valueY(sc.Midpoints[m.level]))
+	}
+	if len(markers) > 0 {
+		drawLegend(c, l, th, markerLegend(markers))
+	}
+	drawHAverageLine(c, l, th, p, valueY(avg), inValley)
+	if opts.ShowConfidence && !opts.Aggregation.isMean() {
+		drawHMeanLine(c, l, th, p, valueY(st.Mean))
+	}
+
+	headerRight := l.right()
+	if opts.ShowAgreement {
+		headerRight = drawAgreementBadge(c, l, th, st) - l.px(headerLabelGap)
+	}
+	drawHeaderLabels(c, l, th, header, headerRight)
+}
+
+// drawHCountLines draws the vertical grid lines of the count axis.
+func drawHCountLines(c Canvas, l layout, th *Theme, p hplot, maxVotes int) {
+	setColor(c, th.GridLine)
+	c.SetLineWidth(l.px(1))
+	for i := 0; i <= 4; i++ {
+		x := p.left + float64((maxVotes*i)/4)/float64(maxVotes)*p.width()
+		c.MoveTo(x, p.top)
+		c.LineTo(x, p.bottom)
+		c.Stroke()
+	}
+}
+
+// drawCountAxis labels the count axis below the plot, in votes or percent
+// as axis measures them.
+func drawCountAxis(c Canvas, l layout, th *Theme, p hplot, maxVotes int, axis YAxis) {
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(12))
+	for i := 0; i <= 4; i++ {
+		value := (maxVotes * i) / 4
+		x := p.left + float64(value)/float64(maxVotes)*p.width()
+		label := axis.tickLabel(value)
+		extents := c.TextExtents(label)
+		drawTextWithShadow(c, l, th, label, x-extents.Width/2, p.bottom+l.px(22))
+	}
+}
+
+func drawHBars(c Canvas, l layout, th *Theme, sc *Scale, p hplot, votes Tally, minIdx, maxIdx, maxVotes int, patterns bool) {
+	barHeight := p.barHeight()
+	radius := math.Min(l.px(BarRadius), barHeight/2)
+
+	setColor(c, th.BarShadow)
+	for i := minIdx; i <= maxIdx; i++ {
+		voteCount := votes[sc.Levels[i]]
+		if voteCount == 0 {
+			continue
+		}
+		w := (voteCount / float64(maxVotes)) * p.width()
+		drawRoundedRightRect(c, p.left+l.px(ShadowOffsetX), p.barY(i-minIdx)+l.px(ShadowOffsetY), w, barHeight, radius)
+		c.Fill()
+	}
+
+	for i := minIdx; i <= maxIdx; i++ {
+		level := sc.Levels[i]
+		voteCount := votes[level]
+		if voteCount == 0 {
+			continue
+		}
+		y := p.barY(i - minIdx)
+		w := (voteCount / float64(maxVotes)) * p.width()
+
+		color := th.barColor(level)
+		setColor(c, color)
+		drawRoundedRightRect(c, p.left, y, w, barHeight, radius)
+		c.Fill()
+
+		if patterns {
+			drawRightBarPattern(c, l, PatternForLevel(i), color, p.left, y, w, barHeight, radius)
+		}
+	}
+}
+
+// drawRoundedRightRect is drawRoundedTopRect for a bar growing to the
+// right: only its right-hand corners are rounded.
+func drawRoundedRightRect(c Canvas, x, y, w, h, r float64) {
+	r = math.Max(math.Min(r, math.Min(w, h/2)), 0)
+
+	c.MoveTo(x, y)
+	c.LineTo(x+w-r, y)
+	c.Arc(x+w-r, y+r, r, 1.5*math.Pi, 2*math.Pi)
+	c.LineTo(x+w, y+h-r)
+	c.Arc(x+w-r, y+h-r, r, 0, 0.5*math.Pi)
+	c.LineTo(x, y+h)
+	c.ClosePath()
+}
+
+// drawLevelLabels right-aligns each level's name against the left edge of
+// the plot, centered on its bar.
+func drawLevelLabels(c Canvas, l layout, th *Theme, sc *Scale, p hplot, minIdx, maxIdx int) {
+	selectLevelFont(c, l)
+	barHeight := p.barHeight()
+	for i := minIdx; i <= maxIdx; i++ {
+		text := strings.ToUpper(sc.Levels[i])
+		extents := c.TextExtents(text)
+		y := p.barY(i-minIdx) + barHeight/2 - extents.YBearing - extents.Height/2
+		drawTextWithShadow(c, l, th, text, p.left-extents.Width-l.px(hLabelGap), y)
+	}
+}
+
+// drawHVoteCounts labels every bar just past its end.
+func drawHVoteCounts(c Canvas, l layout, th *Theme, sc *Scale, p hplot, bars barValues, minIdx, maxIdx, maxVotes int) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(13))
+	barHeight := p.barHeight()
+	for i := minIdx; i <= maxIdx; i++ {
+		level := sc.Levels[i]
+		value := bars.values[level]
+		if value == 0 {
+			continue
+		}
+		label := bars.label(level)
+		extents := c.TextExtents(label)
+		x := p.left + (value/float64(maxVotes))*p.width() + l.px(8)
+		y := p.barY(i-minIdx) + barHeight/2 - extents.YBearing - extents.Height/2
+		drawTextWithShadow(c, l, th, label, x, y)
+	}
+}
+
+// drawHBand is drawBand for a horizontal chart: it shades the plot between
+// y0 and y1.
+func drawHBand(c Canvas, l layout, p hplot, col Color, y0, y1 float64) {
+	if h := l.px(2); y1-y0 < h {
+		mid := (y0 + y1) / 2
+		y0, y1 = mid-h/2, mid+h/2
+	}
+	y0, y1 = math.Max(y0, p.top), math.Min(y1, p.bottom)
+	if y1 <= y0 {
+		return
+	}
+	setColor(c, col)
+	c.Rectangle(p.left, y0, p.width(), y1-y0)
+	c.Fill()
+}
+
+// drawHAverageLine is drawAverageLine for a horizontal chart.
+func drawHAverageLine(c Canvas, l layout, th *Theme, p hplot, y float64, inValley bool) {
+	if inValley {
+		setColor(c, th.Warning)
+	} else {
+		setColor(c, th.AverageLine)
+	}
+	c.SetLineWidth(l.px(2))
+	c.SetDash([]float64{l.px(8), l.px(5)}, 0)
+	c.MoveTo(p.left, y)
+	c.LineTo(p.right, y)
+	c.Stroke()
+	c.SetDash(nil, 0)
+}
+
+// drawHMeanLine is drawMeanLine for a horizontal chart.
+func drawHMeanLine(c Canvas, l layout, th *Theme, p hplot, y float64) {
+	setColor(c, th.AverageLine)
+	c.SetLineWidth(l.px(1))
+	c.MoveTo(p.left, y)
+	c.LineTo(p.right, y)
+	c.Stroke()
+}
+
+// drawHMedianLine is drawMedianLine for a horizontal chart; its pointer
+// sits on the level axis.
+func drawHMedianLine(c Canvas, l layout, th *Theme, p hplot, y float64) {
+	setColor(c, th.MedianLine)
+	c.SetLineWidth(l.px(2))
+	c.MoveTo(p.left, y)
+	c.LineTo(p.right, y)
+	c.Stroke()
+
+	size := l.px(7)
+	c.MoveTo(p.left+size, y)
+	c.LineTo(p.left-size/2, y-size)
+	c.LineTo(p.left-size/2, y+size)
+	c.ClosePath()
+	c.Fill()
+}
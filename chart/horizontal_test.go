@@ -0,0 +1,75 @@
+package chart
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookupOrientation(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Orientation
+		wantErr bool
+	}{
+		{"", OrientationVertical, false},
+		{"vertical", OrientationVertical, false},
+		{"horizontal", OrientationHorizontal, false},
+		{"sideways", "", true},
+	}
+	for _, tt := range tests {
+		got, err := LookupOrientation(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("LookupOrientation(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHPlot(t *testing.T) {
+	c := newImageCanvas(CanvasWidth, CanvasHeight)
+	defer c.Close()
+
+	full := newHPlot(c, defaultLayout, GenjiScale, 0, len(GenjiScale.Levels)-1)
+	narrow := newHPlot(c, defaultLayout, GenjiScale, 0, 2)
+	if full.left <= narrow.left {
+		t.Errorf("plot with VERY HARD + starts at %v, not right of %v", full.left, narrow.left)
+	}
+	if narrow.left < defaultLayout.left() {
+		t.Errorf("plot starts at %v, inside the left margin %v", narrow.left, defaultLayout.left())
+	}
+
+	for _, p := range []hplot{full, narrow} {
+		last := p.barY(p.numBars-1) + p.barHeight()
+		if math.Abs(last-p.bottom) > 1e-9 {
+			t.Errorf("last bar ends at %v, want plot bottom %v", last, p.bottom)
+		}
+		if p.gap > hMaxGapShare*p.height()/float64(p.numBars)+1e-9 {
+			t.Errorf("gap %v exceeds %v of a slot", p.gap, hMaxGapShare)
+		}
+	}
+
+	top := full.valueY(GenjiScale, GenjiScale.Ranges["Easy -"].Lower, 0, 15)
+	bottom := full.valueY(GenjiScale, GenjiScale.Ranges["Hell"].Upper, 0, 15)
+	if top != full.top || bottom != full.bottom {
+		t.Errorf("valueY spans %v..%v, want %v..%v", top, bottom, full.top, full.bottom)
+	}
+}
+
+func TestRenderChartHorizontal(t *testing.T) {
+	useBackend(t, "go")
+	votes := map[string]int{"Easy": 3, "Hard": 1, "Very Hard +": 2, "Extreme": 3}
+	for _, opts := range []Options{
+		{Format: FormatPNG, Orientation: OrientationHorizontal},
+		{Format: FormatPNG, Orientation: OrientationHorizontal, Patterns: true, Theme: "light", Width: 400, Height: 200},
+		{Format: FormatPNG, Orientation: OrientationHorizontal, ShowMedian: true, ShowIQR: true, ShowConfidence: true, ShowAgreement: true},
+		{Format: FormatPNG, Orientation: OrientationHorizontal, ShowConfidence: true, Aggregation: Aggregation{Mode: AggregateMedian}},
+	} {
+		data, err := RenderChart(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderChart(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	if _, err := RenderChart(votes, Options{Format: FormatPNG, Orientation: "diagonal"}); err == nil {
+		t.Error("RenderChart with an unknown orientation succeeded")
+	}
+}
@@ -0,0 +1,90 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	MinCanvasWidth  = 200
+	MaxCanvasWidth  = 4000
+	MinCanvasHeight = 100
+	MaxCanvasHeight = 2000
+	MinScale        = 0.5
+	MaxScale        = 4
+	// MaxPixels caps the rendered bitmap (width*scale x height*scale).
+	MaxPixels = 16_000_000
+)
+
+// ValidateSize checks canvas dimensions and scale factor against the
+// supported bounds. Zero values are treated as "use the default".
+func ValidateSize(width, height int, scale float64) error {
+	o := Options{Width: width, Height: height, Scale: scale}.withDefaults()
+	if o.Width < MinCanvasWidth || o.Width > MaxCanvasWidth {
+		return fmt.Errorf("width must be between %d and %d", MinCanvasWidth, MaxCanvasWidth)
+	}
+	if o.Height < MinCanvasHeight || o.Height > MaxCanvasHeight {
+		return fmt.Errorf("height must be between %d and %d", MinCanvasHeight, MaxCanvasHeight)
+	}
+	if math.IsNaN(o.Scale) || o.Scale < MinScale || o.Scale > MaxScale {
+		return fmt.Errorf("scale must be between %g and %g", float64(MinScale), float64(MaxScale))
+	}
+	if float64(o.Width)*o.Scale*float64(o.Height)*o.Scale > MaxPixels {
+		return errors.New("rendered image too large; reduce width, height or scale")
+	}
+	return nil
+}
+
+// layout maps the design-space constants in render.go (drawn for a
+// CanvasWidth x CanvasHeight canvas at 1x) onto the requested output size.
+// Every length is multiplied by unit, so margins, fonts, radii and shadows
+// shrink or grow together; the plot area absorbs any change in aspect ratio.
+type layout struct {
+	width  float64
+	height float64
+	unit   float64
+	// title is the height of the title block above the header row and
+	// footer the height of the progress footer below the axis labels, in
+	// output pixels; see withTitle and withFooter.
+	title  float64
+	footer float64
+}
+
+func newLayout(width, height int, scale float64) layout {
+	unit := math.Min(float64(width)/CanvasWidth, float64(height)/CanvasHeight) * scale
+	return layout{
+		width:  float64(width) * scale,
+		height: float64(height) * scale,
+		unit:   unit,
+	}
+}
+
+var defaultLayout = newLayout(CanvasWidth, CanvasHeight, 1)
+
+// px converts a design-space length to output pixels.
+func (l layout) px(v float64) float64 {
+	return v * l.unit
+}
+
+func (l layout) pixelSize() (int, int) {
+	return int(math.Round(l.width)), int(math.Round(l.height))
+}
+
+func (l layout) left() float64   { return l.px(LeftMargin) }
+func (l layout) right() float64  { return l.width - l.px(RightMargin) }
+func (l layout) top() float64    { return l.px(TopMargin) + l.title }
+func (l layout) bottom() float64 { return l.height - l.px(BottomMargin) - l.footer }
+
+func (l layout) chartWidth() float64  { return l.right() - l.left() }
+func (l layout) chartHeight() float64 { return l.bottom() - l.top() }
+
+func (l layout) barWidth(numBars int) float64 {
+	return (l.chartWidth() - float64(numBars-1)*l.px(BarGap)) / float64(numBars)
+}
+
+// barX returns the left edge of the bar at position i (0-based within the
+// visible window).
+func (l layout) barX(i, numBars int) float64 {
+	return l.left() + float64(i)*(l.barWidth(numBars)+l.px(BarGap))
+}
@@ -0,0 +1,74 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestValidateSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		scale         float64
+		wantErr       bool
+	}{
+		{"defaults", 0, 0, 0, false},
+		{"thumbnail", 400, 200, 1, false},
+		{"retina", 1000, 500, 3, false},
+		{"too narrow", 100, 500, 1, true},
+		{"too tall", 1000, 5000, 1, true},
+		{"scale too small", 1000, 500, 0.1, true},
+		{"scale too large", 1000, 500, 8, true},
+		{"negative scale", 1000, 500, -1, true},
+		{"too many pixels", 4000, 2000, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSize(tt.width, tt.height, tt.scale)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSize(%d, %d, %g) error = %v, wantErr %v", tt.width, tt.height, tt.scale, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLayoutScalesProportionally(t *testing.T) {
+	base := newLayout(CanvasWidth, CanvasHeight, 1)
+	retina := newLayout(CanvasWidth, CanvasHeight, 2)
+	half := newLayout(CanvasWidth/2, CanvasHeight/2, 1)
+
+	if retina.unit != 2 || half.unit != 0.5 {
+		t.Fatalf("unit = %f / %f, want 2 / 0.5", retina.unit, half.unit)
+	}
+	if math.Abs(retina.chartWidth()-2*base.chartWidth()) > 1e-9 {
+		t.Errorf("retina chartWidth = %f, want %f", retina.chartWidth(), 2*base.chartWidth())
+	}
+	if math.Abs(half.barWidth(5)-base.barWidth(5)/2) > 1e-9 {
+		t.Errorf("half barWidth = %f, want %f", half.barWidth(5), base.barWidth(5)/2)
+	}
+	if got := base.barX(0, 5); got != LeftMargin {
+		t.Errorf("first bar x = %f, want %d", got, LeftMargin)
+	}
+}
+
+func TestRenderChartSize(t *testing.T) {
+	votes := map[string]int{"Hard": 4, "Hard +": 2}
+
+	data, err := RenderChart(votes, Options{Format: FormatPNG, Width: 400, Height: 200, Scale: 2})
+	if err != nil {
+		t.Fatalf("RenderChart error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 800 || b.Dy() != 400 {
+		t.Errorf("image size = %v, want 800x400", b.Size())
+	}
+
+	if _, err := RenderChart(votes, Options{Format: FormatPNG, Width: 10}); err == nil {
+		t.Error("expected error for out-of-range width")
+	}
+}
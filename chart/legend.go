@@ -0,0 +1,54 @@
+package chart
+
+import "math"
+
+const (
+	legendSwatch   = 12
+	legendLine     = 24
+	legendSpacing  = 6
+	legendEntryGap = 24
+)
+
+// legendEntry is one item of the legend row: a swatch of a bar color, a
+// sample of an average line drawn with dash (in design pixels) and a caption.
+type legendEntry struct {
+	swatch Color
+	line   Color
+	dash   []float64
+	text   string
+}
+
+// drawLegend draws entries on one row centered under the x axis labels.
+func drawLegend(c Canvas, l layout, th *Theme, entries []legendEntry) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(12))
+
+	widths := make([]float64, len(entries))
+	var total float64
+	for i, e := range entries {
+		widths[i] = l.px(legendSwatch+legendLine+2*legendSpacing) + c.TextExtents(e.text).Width
+		total += widths[i]
+	}
+	total += l.px(legendEntryGap) * float64(len(entries)-1)
+
+	baseline := l.bottom() + l.px(58)
+	mid := baseline - l.px(4.5)
+	x := math.Max(l.left(), l.left()+(l.chartWidth()-total)/2)
+	for i, e := range entries {
+		setColor(c, e.swatch)
+		drawRoundedRect(c, x, mid-l.px(legendSwatch)/2, l.px(legendSwatch), l.px(legendSwatch), l.px(3))
+		c.Fill()
+
+		lineX := x + l.px(legendSwatch+legendSpacing)
+		setColor(c, e.line)
+		c.SetLineWidth(l.px(2))
+		c.SetDash(scaleDash(l, e.dash), 0)
+		c.MoveTo(lineX, mid)
+		c.LineTo(lineX+l.px(legendLine), mid)
+		c.Stroke()
+		c.SetDash(nil, 0)
+
+		drawTextWithShadow(c, l, th, e.text, lineX+l.px(legendLine+legendSpacing), baseline)
+		x += widths[i] + l.px(legendEntryGap)
+	}
+}
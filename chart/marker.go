@@ -0,0 +1,101 @@
+package chart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// difficultyMarker marks a level the map was rated at, apart from the votes.
+type difficultyMarker struct {
+	name  string
+	level string
+	color Color
+}
+
+// validateMarkers checks that the submitted and official difficulties are
+// levels of sc.
+func (o Options) validateMarkers(sc *Scale) error {
+	if o.SubmittedDifficulty != "" {
+		if _, ok := sc.Index(o.SubmittedDifficulty); !ok {
+			return fmt.Errorf("invalid submitted difficulty: %s", o.SubmittedDifficulty)
+		}
+	}
+	if o.OfficialDifficulty != "" {
+		if _, ok := sc.Index(o.OfficialDifficulty); !ok {
+			return fmt.Errorf("invalid official difficulty: %s", o.OfficialDifficulty)
+		}
+	}
+	return nil
+}
+
+// markers returns the difficulty markers o asks for, colored from th.
+func (o Options) markers(th *Theme) []difficultyMarker {
+	var markers []difficultyMarker
+	if o.SubmittedDifficulty != "" {
+		markers = append(markers, difficultyMarker{name: "SUBMITTED", level: o.SubmittedDifficulty, color: th.SubmittedMarker})
+	}
+	if o.OfficialDifficulty != "" {
+		markers = append(markers, difficultyMarker{name: "OFFICIAL", level: o.OfficialDifficulty, color: th.OfficialMarker})
+	}
+	return markers
+}
+
+// markerLevels returns the levels of markers, for widening the window.
+func markerLevels(markers []difficultyMarker) []string {
+	levels := make([]string, len(markers))
+	for i, m := range markers {
+		levels[i] = m.level
+	}
+	return levels
+}
+
+// markerLegend names each marker in the legend row under the chart.
+func markerLegend(markers []difficultyMarker) []legendEntry {
+	entries := make([]legendEntry, len(markers))
+	for i, m := range markers {
+		entries[i] = legendEntry{swatch: m.color, line: m.color, dash: markerDash, text: m.name + ": " + strings.ToUpper(m.level)}
+	}
+	return entries
+}
+
+// markerDash is the dot pattern of marker lines, which sets them apart from
+// the dashed average and solid median lines. The dots of the second marker
+// fall in the gaps of the first, so both colors show when they coincide.
+var markerDash = []float64{2, 4}
+
+const markerSize = 6
+
+// drawMarkerLine draws the i'th marker as a dotted line through the plot at
+// x with a diamond on the x axis.
+func drawMarkerLine(c Canvas, l layout, col Color, i int, x float64) {
+	setColor(c, col)
+	c.SetLineWidth(l.px(2))
+	c.SetDash(scaleDash(l, markerDash), l.px(3*float64(i)))
+	c.MoveTo(x, l.top())
+	c.LineTo(x, l.bottom())
+	c.Stroke()
+	c.SetDash(nil, 0)
+	drawDiamond(c, l.px(markerSize), x, l.bottom())
+}
+
+// drawHMarkerLine is drawMarkerLine for a horizontal chart; its diamond
+// sits on the level axis.
+func drawHMarkerLine(c Canvas, l layout, p hplot, col Color, i int, y float64) {
+	setColor(c, col)
+	c.SetLineWidth(l.px(2))
+	c.SetDash(scaleDash(l, markerDash), l.px(3*float64(i)))
+	c.MoveTo(p.left, y)
+	c.LineTo(p.right, y)
+	c.Stroke()
+	c.SetDash(nil, 0)
+	drawDiamond(c, l.px(markerSize), p.left, y)
+}
+
+func drawDiamond(c Canvas, size, x, y float64) {
+	c.MoveTo(x, y-size)
+	c.LineTo(x+size, y)
+	c.LineTo(x, y+size)
+	c.LineTo(x-size, y)
+	c.ClosePath()
+	c.Fill()
+}
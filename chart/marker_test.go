@@ -0,0 +1,74 @@
+package chart
+
+import "testing"
+
+func TestCalculateWindowMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		votes   map[string]int
+		markers []string
+		minIdx  int
+		maxIdx  int
+	}{
+		{"marker on a voted level", map[string]int{"Hard": 5}, []string{"Hard"}, 5, 9},
+		{"marker below votes", map[string]int{"Hard": 5}, []string{"Medium"}, 3, 8},
+		{"marker above votes", map[string]int{"Hard": 5}, []string{"Extreme"}, 6, 14},
+		{"markers on both sides", map[string]int{"Hard": 5}, []string{"Easy", "Hell"}, 0, 15},
+		{"unknown marker ignored", map[string]int{"Medium": 1}, []string{"Nope"}, 2, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minIdx, maxIdx := CalculateWindow(tt.votes, tt.markers...)
+			if minIdx != tt.minIdx || maxIdx != tt.maxIdx {
+				t.Errorf("CalculateWindow() = (%d, %d), want (%d, %d)", minIdx, maxIdx, tt.minIdx, tt.maxIdx)
+			}
+		})
+	}
+}
+
+func TestOptionsMarkers(t *testing.T) {
+	opts := Options{SubmittedDifficulty: "Medium", OfficialDifficulty: "Hard"}
+	markers := opts.markers(DarkTheme)
+	if len(markers) != 2 || markers[0].level != "Medium" || markers[1].level != "Hard" {
+		t.Fatalf("markers() = %+v", markers)
+	}
+	if markers[0].color != DarkTheme.SubmittedMarker || markers[1].color != DarkTheme.OfficialMarker {
+		t.Errorf("markers() colors = %v, %v", markers[0].color, markers[1].color)
+	}
+	if markers := (Options{OfficialDifficulty: "Hard"}).markers(DarkTheme); len(markers) != 1 || markers[0].name != "OFFICIAL" {
+		t.Errorf("markers() = %+v, want only the official marker", markers)
+	}
+}
+
+func TestRenderChartMarkers(t *testing.T) {
+	useBackend(t, "go")
+	votes := map[string]int{"Hard": 3, "Hard +": 1}
+	for _, opts := range []Options{
+		{Format: FormatPNG, SubmittedDifficulty: "Medium", OfficialDifficulty: "Extreme"},
+		{Format: FormatPNG, SubmittedDifficulty: "Hard", OfficialDifficulty: "Hard"},
+		{Format: FormatPNG, OfficialDifficulty: "Easy", Orientation: OrientationHorizontal},
+		{Format: FormatPNG, SubmittedDifficulty: "Hell", Type: ChartDonut},
+	} {
+		data, err := RenderChart(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderChart(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	groups := []Group{{Name: "a", Votes: Tally{"Hard": 2}}, {Name: "b", Votes: Tally{"Hard +": 1}}}
+	if _, err := RenderGroups(groups, Options{Format: FormatPNG, OfficialDifficulty: "Very Hard"}); err != nil {
+		t.Errorf("RenderGroups() with a marker: %v", err)
+	}
+	if _, err := RenderComparison(Tally{"Medium": 2}, TallyCounts(votes), Options{Format: FormatPNG, SubmittedDifficulty: "Easy"}); err != nil {
+		t.Errorf("RenderComparison() with a marker: %v", err)
+	}
+
+	for _, opts := range []Options{
+		{Format: FormatPNG, SubmittedDifficulty: "Hard ++"},
+		{Format: FormatPNG, OfficialDifficulty: "Nope"},
+	} {
+		if _, err := RenderChart(votes, opts); err == nil {
+			t.Errorf("RenderChart(%+v) accepted an unknown level", opts)
+		}
+	}
+}
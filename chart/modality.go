@@ -0,0 +1,100 @@
+package chart
+
+const (
+	// minPeakShare is the smallest share of all votes a local maximum needs
+	// to count as a peak, so single stray votes are not reported.
+	minPeakShare = 0.15
+	// valleyRatio is how deep the dip between two peaks must be, relative
+	// to the smaller peak, for them to count as separate peaks.
+	valleyRatio = 0.5
+)
+
+// findPeaks returns the indices of the distinct peaks in a vote histogram
+// ordered by level. A run of equal counts is one candidate, located at its
+// middle. Candidates below minPeakShare are dropped, and neighboring peaks
+// not separated by a valley of at most valleyRatio times the smaller one are
+// merged into the higher.
+func findPeaks(counts []float64, total float64) []int {
+	if total == 0 {
+		return nil
+	}
+	at := func(i int) float64 {
+		if i < 0 || i >= len(counts) {
+			return 0
+		}
+		return counts[i]
+	}
+
+	var peaks []int
+	for i := 0; i < len(counts); {
+		j := i
+		for j+1 < len(counts) && counts[j+1] == counts[i] {
+			j++
+		}
+		v := counts[i]
+		if v > at(i-1) && v > at(j+1) && v >= minPeakShare*total {
+			peaks = append(peaks, (i+j)/2)
+		}
+		i = j + 1
+	}
+
+	for merged := true; merged; {
+		merged = false
+		for k := 1; k < len(peaks); k++ {
+			a, b := peaks[k-1], peaks[k]
+			lower := min(counts[a], counts[b])
+			if valley(counts, a, b) <= valleyRatio*lower {
+				continue
+			}
+			drop := k
+			if counts[a] < counts[b] {
+				drop = k - 1
+			}
+			peaks = append(peaks[:drop], peaks[drop+1:]...)
+			merged = true
+			break
+		}
+	}
+	return peaks
+}
+
+// valley returns the lowest count strictly between levels a and b.
+func valley(counts []float64, a, b int) float64 {
+	low := min(counts[a], counts[b])
+	for i := a + 1; i < b; i++ {
+		low = min(low, counts[i])
+	}
+	return low
+}
+
+// valleyAt reports whether avg maps to a level in a valley between two of
+// the peaks in st.
+func (s *Scale) valleyAt(st Stats, avg float64) bool {
+	idx, ok := s.Index(s.AverageToLabel(avg))
+	if !ok {
+		return false
+	}
+	counts := make([]float64, len(st.Shares))
+	for i, sh := range st.Shares {
+		counts[i] = sh.Votes
+	}
+	peaks := make([]int, 0, len(st.Peaks))
+	for _, level := range st.Peaks {
+		if i, ok := s.Index(level); ok {
+			peaks = append(peaks, i)
+		}
+	}
+	return inValley(counts, peaks, idx)
+}
+
+// inValley reports whether level idx lies in a valley between two of the
+// given peaks, deep enough to have separated them.
+func inValley(counts []float64, peaks []int, idx int) bool {
+	for k := 1; k < len(peaks); k++ {
+		a, b := peaks[k-1], peaks[k]
+		if a < idx && idx < b {
+			return counts[idx] <= valleyRatio*min(counts[a], counts[b])
+		}
+	}
+	return false
+}
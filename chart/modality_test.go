@@ -0,0 +1,73 @@
+package chart
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindPeaks(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []float64
+		want   []int
+	}{
+		{"empty", []float64{0, 0, 0}, nil},
+		{"single", []float64{0, 3, 0}, []int{1}},
+		{"unimodal", []float64{1, 4, 8, 5, 2}, []int{2}},
+		{"two separated peaks", []float64{6, 2, 0, 0, 6}, []int{0, 4}},
+		{"shallow dip merges", []float64{5, 4, 6, 0, 0}, []int{2}},
+		{"dip to exactly half", []float64{6, 3, 6}, []int{0, 2}},
+		{"plateau is one peak", []float64{0, 4, 4, 4, 0}, []int{2}},
+		{"stray vote ignored", []float64{0, 10, 8, 0, 0, 1}, []int{1}},
+		{"three peaks", []float64{5, 0, 5, 0, 5}, []int{0, 2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var total float64
+			for _, c := range tt.counts {
+				total += c
+			}
+			if got := findPeaks(tt.counts, total); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findPeaks(%v) = %v, want %v", tt.counts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInValley(t *testing.T) {
+	counts := []float64{6, 1, 3, 0, 6}
+	peaks := []int{0, 4}
+	tests := []struct {
+		idx  int
+		want bool
+	}{
+		{0, false},
+		{1, true},
+		{2, true},
+		{3, true},
+		{4, false},
+	}
+	for _, tt := range tests {
+		if got := inValley(counts, peaks, tt.idx); got != tt.want {
+			t.Errorf("inValley(%d) = %v, want %v", tt.idx, got, tt.want)
+		}
+	}
+	if inValley([]float64{6, 4, 6}, []int{0, 2}, 1) {
+		t.Error("a level with more than half the smaller peak is not a valley")
+	}
+}
+
+func TestStatsSplitVote(t *testing.T) {
+	st := CalculateStats(map[string]int{"Medium": 5, "Very Hard": 5})
+	if !st.Multimodal || !reflect.DeepEqual(st.Peaks, []string{"Medium", "Very Hard"}) {
+		t.Errorf("Peaks = %v, Multimodal = %v", st.Peaks, st.Multimodal)
+	}
+	if st.MeanLevel != "Hard" || !st.AverageInValley {
+		t.Errorf("MeanLevel = %q, AverageInValley = %v; want Hard, true", st.MeanLevel, st.AverageInValley)
+	}
+
+	st = CalculateStats(map[string]int{"Medium": 5, "Medium +": 4, "Hard -": 2})
+	if st.Multimodal || st.AverageInValley || !reflect.DeepEqual(st.Peaks, []string{"Medium"}) {
+		t.Errorf("unimodal votes: Peaks = %v, Multimodal = %v, AverageInValley = %v", st.Peaks, st.Multimodal, st.AverageInValley)
+	}
+}
@@ -0,0 +1,110 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Palette is a color ramp sampled evenly across the difficulty levels, from
+// the easiest level at the first stop to the hardest at the last.
+type Palette struct {
+	Name  string
+	Stops []Color
+}
+
+// The ramps below are monotonic in lightness, so neighboring levels stay
+// distinguishable under every common color vision deficiency. The darkest
+// end of each reference ramp is trimmed to keep contrast with dark themes.
+var (
+	ViridisPalette = &Palette{Name: "viridis", Stops: hexStops(
+		"#46337e", "#365c8d", "#277f8e", "#1fa187", "#4ac16d", "#a0da39", "#fde725",
+	)}
+	CividisPalette = &Palette{Name: "cividis", Stops: hexStops(
+		"#2a3f6e", "#3b496c", "#575d6d", "#707173", "#8a8779", "#a69d75", "#c4b56c", "#e4cf5b", "#fee838",
+	)}
+	MagmaPalette = &Palette{Name: "magma", Stops: hexStops(
+		"#4f127b", "#812581", "#b5367a", "#e55064", "#fb8761", "#fec287", "#fcfdbf",
+	)}
+)
+
+var palettes = map[string]*Palette{
+	ViridisPalette.Name: ViridisPalette,
+	CividisPalette.Name: CividisPalette,
+	MagmaPalette.Name:   MagmaPalette,
+	// Aliases named after the deficiency they are chosen for.
+	"deuteranopia": CividisPalette,
+	"protanopia":   ViridisPalette,
+	"tritanopia":   MagmaPalette,
+}
+
+func hexStops(hexes ...string) []Color {
+	stops := make([]Color, len(hexes))
+	for i, hex := range hexes {
+		stops[i] = hexColor(hex)
+	}
+	return stops
+}
+
+// LookupPalette returns the named palette. An empty name returns nil,
+// meaning the theme's own difficulty colors are used.
+func LookupPalette(name string) (*Palette, error) {
+	if name == "" {
+		return nil, nil
+	}
+	p, ok := palettes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown palette: %s (available: %v)", name, PaletteNames())
+	}
+	return p, nil
+}
+
+func PaletteNames() []string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// At samples the ramp at t in [0, 1] with linear interpolation.
+func (p *Palette) At(t float64) Color {
+	if len(p.Stops) == 1 {
+		return p.Stops[0]
+	}
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(p.Stops)-1)
+	i := int(pos)
+	if i >= len(p.Stops)-1 {
+		return p.Stops[len(p.Stops)-1]
+	}
+	f := pos - float64(i)
+	a, b := p.Stops[i], p.Stops[i+1]
+	return Color{
+		R: a.R + (b.R-a.R)*f,
+		G: a.G + (b.G-a.G)*f,
+		B: a.B + (b.B-a.B)*f,
+		A: a.A + (b.A-a.A)*f,
+	}
+}
+
+// Colors assigns each level its evenly spaced sample of the ramp.
+func (p *Palette) Colors(levels []string) map[string]Color {
+	out := make(map[string]Color, len(levels))
+	for i, level := range levels {
+		t := 0.0
+		if len(levels) > 1 {
+			t = float64(i) / float64(len(levels)-1)
+		}
+		out[level] = p.At(t)
+	}
+	return out
+}
+
+// withPalette returns a copy of th whose bar colors come from p.
+func (th *Theme) withPalette(p *Palette, levels []string) *Theme {
+	out := *th
+	out.DifficultyColors = p.Colors(levels)
+	return &out
+}
@@ -0,0 +1,63 @@
+package chart
+
+import "testing"
+
+func TestLookupPalette(t *testing.T) {
+	p, err := LookupPalette("")
+	if err != nil || p != nil {
+		t.Errorf("LookupPalette(\"\") = %v, %v; want nil, nil", p, err)
+	}
+	p, err = LookupPalette("deuteranopia")
+	if err != nil || p != CividisPalette {
+		t.Errorf("LookupPalette(deuteranopia) = %v, %v; want cividis", p, err)
+	}
+	if _, err := LookupPalette("rainbow"); err == nil {
+		t.Error("expected error for unknown palette")
+	}
+}
+
+func TestPaletteAt(t *testing.T) {
+	p := &Palette{Stops: []Color{RGB(0, 0, 0), RGB(1, 1, 1)}}
+	tests := []struct {
+		t    float64
+		want Color
+	}{
+		{0, RGB(0, 0, 0)},
+		{0.5, RGB(0.5, 0.5, 0.5)},
+		{1, RGB(1, 1, 1)},
+		{-1, RGB(0, 0, 0)},
+		{2, RGB(1, 1, 1)},
+	}
+	for _, tt := range tests {
+		if got := p.At(tt.t); got != tt.want {
+			t.Errorf("At(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestPaletteColorsSpanRamp(t *testing.T) {
+	for _, name := range PaletteNames() {
+		p, _ := LookupPalette(name)
+		colors := p.Colors(DifficultyLevels)
+		if len(colors) != len(DifficultyLevels) {
+			t.Fatalf("%s: got %d colors, want %d", name, len(colors), len(DifficultyLevels))
+		}
+		first, last := DifficultyLevels[0], DifficultyLevels[len(DifficultyLevels)-1]
+		if colors[first] != p.Stops[0] || colors[last] != p.Stops[len(p.Stops)-1] {
+			t.Errorf("%s: ramp endpoints not used for %q and %q", name, first, last)
+		}
+	}
+}
+
+func TestWithPaletteCopiesTheme(t *testing.T) {
+	th := DarkTheme.withPalette(ViridisPalette, DifficultyLevels)
+	if th == DarkTheme {
+		t.Fatal("withPalette returned the shared theme")
+	}
+	if got, want := th.barColor("Easy -"), ViridisPalette.Stops[0]; got != want {
+		t.Errorf("barColor(Easy -) = %v, want %v", got, want)
+	}
+	if got, want := DarkTheme.barColor("Easy -"), hexColor(DifficultyColors["Easy -"]); got != want {
+		t.Errorf("dark theme modified: %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package chart
+
+import "math"
+
+// Pattern is a fill texture drawn over a bar so levels can be told apart
+// without relying on color.
+type Pattern int
+
+const (
+	PatternSolid Pattern = iota
+	PatternDiagonal
+	PatternDots
+	PatternHorizontal
+	PatternBackDiagonal
+	PatternCross
+	PatternVertical
+	PatternDiagonalCross
+	// PatternChecker takes the place of PatternSolid in the dense cycle.
+	PatternChecker
+)
+
+// PatternDense is set on a pattern to draw it at a tighter spacing.
+const PatternDense Pattern = 1 << 4
+
+const (
+	// patternCycle is the number of levels PatternForLevel gives distinct
+	// patterns before it repeats them densely.
+	patternCycle        = 8
+	patternSpacing      = 9
+	patternDenseSpacing = 5
+	patternLineWidth    = 2
+	patternDotRadius    = 2
+)
+
+// PatternForLevel cycles through the patterns by level index so that
+// adjacent levels never share one. The second run of patternCycle levels
+// gets the dense variants, with PatternChecker for the solid one, so every
+// level of a 16-level scale has its own texture; longer scales repeat every
+// 2*patternCycle levels.
+func PatternForLevel(idx int) Pattern {
+	p := Pattern(idx % patternCycle)
+	if idx/patternCycle%2 == 1 {
+		if p == PatternSolid {
+			p = PatternChecker
+		}
+		p |= PatternDense
+	}
+	return p
+}
+
+// patternColor picks a translucent ink that contrasts with the bar color.
+func patternColor(bar Color) Color {
+	if bar.luminance() > 0.5 {
+		return RGBA(0, 0, 0, 0.35)
+	}
+	return RGBA(1, 1, 1, 0.45)
+}
+
+type rect struct {
+	x0, y0, x1, y1 float64
+}
+
+// drawBarPattern textures a bar drawn by drawRoundedTopRect. The bar is
+// treated as two disjoint rectangles, the body below the corner radius and
+// the band between the corners, so that hatching never spills outside the
+// rounded top and no stroke is drawn twice.
+func drawBarPattern(c Canvas, l layout, p Pattern, bar Color, x, y, w, h, r float64) {
+	if p == PatternSolid || h <= 0 {
+		return
+	}
+	if h < r {
+		r = h
+	}
+	regions := []rect{
+		{x, y + r, x + w, y + h},
+		{x + r, y, x + w - r, y + r},
+	}
+	drawPattern(c, l, p, bar, regions, x, y, w, h)
+}
+
+// drawRightBarPattern is drawBarPattern for a bar drawn by
+// drawRoundedRightRect.
+func drawRightBarPattern(c Canvas, l layout, p Pattern, bar Color, x, y, w, h, r float64) {
+	if p == PatternSolid || w <= 0 {
+		return
+	}
+	r = math.Min(r, math.Min(w, h/2))
+	regions := []rect{
+		{x, y, x + w - r, y + h},
+		{x + w - r, y + r, x + w, y + h - r},
+	}
+	drawPattern(c, l, p, bar, regions, x, y, w, h)
+}
+
+// drawPattern strokes p over the bounding box x, y, w, h, clipped to
+// regions.
+func drawPattern(c Canvas, l layout, p Pattern, bar Color, regions []rect, x, y, w, h float64) {
+	setColor(c, patternColor(bar))
+	c.SetLineWidth(l.px(patternLineWidth))
+	c.SetDash(nil, 0)
+
+	spacing := l.px(patternSpacing)
+	if p&PatternDense != 0 {
+		spacing = l.px(patternDenseSpacing)
+	}
+	switch p &^ PatternDense {
+	case PatternDiagonal:
+		hatch(c, regions, x, y, w, h, spacing, 1)
+	case PatternBackDiagonal:
+		hatch(c, regions, x, y, w, h, spacing, -1)
+	case PatternDiagonalCross:
+		hatch(c, regions, x, y, w, h, spacing*1.5, 1)
+		hatch(c, regions, x, y, w, h, spacing*1.5, -1)
+	case PatternHorizontal:
+		for ly := y + h - spacing/2; ly > y; ly -= spacing {
+			strokeClipped(c, regions, point{x, ly}, point{x + w, ly})
+		}
+	case PatternVertical:
+		for lx := x + spacing/2; lx < x+w; lx += spacing {
+			strokeClipped(c, regions, point{lx, y}, point{lx, y + h})
+		}
+	case PatternCross:
+		for ly := y + h - spacing; ly > y; ly -= spacing * 1.5 {
+			strokeClipped(c, regions, point{x, ly}, point{x + w, ly})
+		}
+		for lx := x + spacing; lx < x+w; lx += spacing * 1.5 {
+			strokeClipped(c, regions, point{lx, y}, point{lx, y + h})
+		}
+	case PatternDots:
+		radius := l.px(patternDotRadius)
+		for dy := y + h - spacing/2; dy-radius > y; dy -= spacing {
+			for dx := x + spacing/2; dx+radius < x+w; dx += spacing {
+				if insideAny(regions, dx, dy, radius) {
+					c.MoveTo(dx+radius, dy)
+					c.Arc(dx, dy, radius, 0, 2*math.Pi)
+					c.ClosePath()
+				}
+			}
+		}
+		c.Fill()
+	case PatternChecker:
+		for row, cy := 0, y+h; cy > y; row, cy = row+1, cy-spacing {
+			for col, cx := 0, x; cx < x+w; col, cx = col+1, cx+spacing {
+				if (row+col)%2 == 0 {
+					fillClipped(c, regions, rect{cx, math.Max(cy-spacing, y), math.Min(cx+spacing, x+w), cy})
+				}
+			}
+		}
+		c.Fill()
+	}
+}
+
+// fillClipped adds the parts of sq that lie within regions to the path.
+func fillClipped(c Canvas, regions []rect, sq rect) {
+	for _, r := range regions {
+		x0, y0 := math.Max(sq.x0, r.x0), math.Max(sq.y0, r.y0)
+		x1, y1 := math.Min(sq.x1, r.x1), math.Min(sq.y1, r.y1)
+		if x1 > x0 && y1 > y0 {
+			c.Rectangle(x0, y0, x1-x0, y1-y0)
+		}
+	}
+}
+
+// hatch draws 45° lines across the bounding box; dir 1 rises to the right
+// and -1 falls to the right.
+func hatch(c Canvas, regions []rect, x, y, w, h, spacing, dir float64) {
+	for offset := -h; offset < w; offset += spacing {
+		if dir > 0 {
+			strokeClipped(c, regions, point{x + offset, y + h}, point{x + offset + h, y})
+		} else {
+			strokeClipped(c, regions, point{x + offset, y}, point{x + offset + h, y + h})
+		}
+	}
+}
+
+func strokeClipped(c Canvas, regions []rect, a, b point) {
+	for _, r := range regions {
+		if p, q, ok := clipSegment(a, b, r); ok {
+			c.MoveTo(p.x, p.y)
+			c.LineTo(q.x, q.y)
+			c.Stroke()
+		}
+	}
+}
+
+func insideAny(regions []rect, x, y, radius float64) bool {
+	for _, r := range regions {
+		if x-radius >= r.x0 && x+radius <= r.x1 && y-radius >= r.y0 && y+radius <= r.y1 {
+			return true
+		}
+	}
+	return false
+}
+
+// clipSegment clips the segment a-b to r using the Liang-Barsky algorithm.
+func clipSegment(a, b point, r rect) (point, point, bool) {
+	if r.x1 <= r.x0 || r.y1 <= r.y0 {
+		return point{}, point{}, false
+	}
+	dx, dy := b.x-a.x, b.y-a.y
+	t0, t1 := 0.0, 1.0
+	edges := [4][2]float64{
+		{-dx, a.x - r.x0},
+		{dx, r.x1 - a.x},
+		{-dy, a.y - r.y0},
+		{dy, r.y1 - a.y},
+	}
+	for _, e := range edges {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return point{}, point{}, false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+		if t0 >= t1 {
+			return point{}, point{}, false
+		}
+	}
+	return point{a.x + t0*dx, a.y + t0*dy}, point{a.x + t1*dx, a.y + t1*dy}, true
+}
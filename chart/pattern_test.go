@@ -0,0 +1,61 @@
+package chart
+
+import "testing"
+
+func TestPatternForLevel(t *testing.T) {
+	for i := 1; i < len(DifficultyLevels); i++ {
+		if PatternForLevel(i) == PatternForLevel(i-1) {
+			t.Errorf("levels %d and %d share a pattern", i-1, i)
+		}
+	}
+	seen := make(map[Pattern]string)
+	for i, level := range DifficultyLevels {
+		p := PatternForLevel(i)
+		if other, ok := seen[p]; ok {
+			t.Errorf("%s and %s share pattern %d", other, level, p)
+		}
+		seen[p] = level
+	}
+	if PatternForLevel(patternCycle) != PatternChecker|PatternDense {
+		t.Errorf("PatternForLevel(%d) = %d, want the dense checker", patternCycle, PatternForLevel(patternCycle))
+	}
+	if PatternForLevel(2*patternCycle) != PatternForLevel(0) {
+		t.Error("patterns should cycle")
+	}
+}
+
+func TestClipSegment(t *testing.T) {
+	r := rect{0, 0, 10, 10}
+	tests := []struct {
+		name   string
+		a, b   point
+		ok     bool
+		p0, p1 point
+	}{
+		{"inside", point{1, 1}, point{9, 9}, true, point{1, 1}, point{9, 9}},
+		{"crossing", point{-5, 5}, point{15, 5}, true, point{0, 5}, point{10, 5}},
+		{"diagonal", point{-5, 15}, point{15, -5}, true, point{0, 10}, point{10, 0}},
+		{"outside", point{-5, -5}, point{-1, 20}, false, point{}, point{}},
+		{"parallel outside", point{-1, 0}, point{-1, 10}, false, point{}, point{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p0, p1, ok := clipSegment(tt.a, tt.b, r)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if p0 != tt.p0 || p1 != tt.p1 {
+				t.Errorf("got %v-%v, want %v-%v", p0, p1, tt.p0, tt.p1)
+			}
+		})
+	}
+}
+
+func TestClipSegmentEmptyRect(t *testing.T) {
+	if _, _, ok := clipSegment(point{0, 0}, point{5, 5}, rect{3, 3, 3, 8}); ok {
+		t.Error("expected no segment for a zero-width rect")
+	}
+}
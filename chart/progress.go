@@ -0,0 +1,105 @@
+package chart
+
+import (
+	"errors"
+	"math"
+)
+
+// Progress measures the votes cast against the number a playtest needs
+// before it closes.
+type Progress struct {
+	Required int
+	Votes    float64
+	// Remaining is the number of votes still needed, zero once Reached.
+	Remaining float64
+	// Percent is Votes as a share of Required, capped at 100.
+	Percent float64
+	Reached bool
+}
+
+// VoteProgress compares votes with required, which must be positive.
+func VoteProgress(votes float64, required int) Progress {
+	req := float64(required)
+	return Progress{
+		Required:  required,
+		Votes:     votes,
+		Remaining: math.Max(req-votes, 0),
+		Percent:   math.Min(votes/req*100, 100),
+		Reached:   votes >= req,
+	}
+}
+
+// votesCast returns the number of votes behind votes for the progress
+// footer; see Options.VotesCast.
+func (o Options) votesCast(votes Tally) float64 {
+	switch {
+	case o.timeline != nil:
+		return float64(len(o.timeline))
+	case o.VotesCast > 0:
+		return float64(o.VotesCast)
+	}
+	return sumTally(votes)
+}
+
+// ValidateRequiredVotes checks a required vote count; zero means none.
+func ValidateRequiredVotes(required int) error {
+	if required < 0 {
+		return errors.New("required votes must not be negative")
+	}
+	return nil
+}
+
+const (
+	// progressFooter is the height the progress footer adds below the
+	// chart.
+	progressFooter = 28
+	progressTrack  = 8
+	progressGap    = 12
+)
+
+// withFooter reserves room for the progress footer when required is set.
+func (l layout) withFooter(required int) layout {
+	l.footer = 0
+	if required > 0 {
+		l.footer = l.px(progressFooter)
+	}
+	return l
+}
+
+// drawProgress draws the footer: the vote count against the requirement,
+// a track filled up to it and the percentage, or COMPLETE in the
+// consensus color once the requirement is met.
+func drawProgress(c Canvas, l layout, th *Theme, p Progress) {
+	baseline := l.height - l.px(14)
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(12))
+
+	label := "VOTES: " + formatCount(p.Votes) + " / " + formatInt(p.Required)
+	status := formatInt(int(math.Floor(p.Percent))) + "%"
+	fill := th.MedianLine
+	if p.Reached {
+		status = "COMPLETE"
+		fill = th.ConsensusColors[ConsensusStrong]
+	}
+	labelW := c.TextExtents(label).Width
+	statusW := c.TextExtents(status).Width
+	drawTextWithShadow(c, l, th, label, l.left(), baseline)
+	drawTextWithShadow(c, l, th, status, l.right()-statusW, baseline)
+
+	x0 := l.left() + labelW + l.px(progressGap)
+	x1 := l.right() - statusW - l.px(progressGap)
+	if x1 <= x0 {
+		return
+	}
+	h := l.px(progressTrack)
+	y := baseline - l.px(4.5) - h/2
+
+	setColor(c, th.GridLine)
+	drawRoundedRect(c, x0, y, x1-x0, h, h/2)
+	c.Fill()
+	if w := (x1 - x0) * p.Percent / 100; w > 0 {
+		setColor(c, fill)
+		drawRoundedRect(c, x0, y, w, h, h/2)
+		c.Fill()
+	}
+}
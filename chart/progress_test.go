@@ -0,0 +1,92 @@
+package chart
+
+import "testing"
+
+func TestVoteProgress(t *testing.T) {
+	tests := []struct {
+		name      string
+		votes     float64
+		required  int
+		remaining float64
+		percent   float64
+		reached   bool
+	}{
+		{"none yet", 0, 10, 10, 0, false},
+		{"partway", 3, 12, 9, 25, false},
+		{"weighted", 2.5, 10, 7.5, 25, false},
+		{"exactly met", 10, 10, 0, 100, true},
+		{"over", 15, 10, 0, 100, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := VoteProgress(tt.votes, tt.required)
+			if p.Required != tt.required || p.Votes != tt.votes {
+				t.Errorf("VoteProgress() = %+v, want %v of %d votes", p, tt.votes, tt.required)
+			}
+			if p.Remaining != tt.remaining || p.Percent != tt.percent || p.Reached != tt.reached {
+				t.Errorf("VoteProgress() = %+v, want remaining %v, percent %v, reached %v", p, tt.remaining, tt.percent, tt.reached)
+			}
+		})
+	}
+}
+
+func TestOptionsVotesCast(t *testing.T) {
+	weighted := Tally{"Hard": 1.5, "Easy": 0.5}
+	tests := []struct {
+		name string
+		opts Options
+		want float64
+	}{
+		{"tally total", Options{}, 2},
+		{"votes cast", Options{VotesCast: 4}, 4},
+		{"timeline counts votes", Options{VotesCast: 9, timeline: []TimedVote{
+			{Level: "Hard", Weight: 3},
+			{Level: "Easy", Weight: 3},
+		}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.votesCast(weighted); got != tt.want {
+				t.Errorf("votesCast() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutWithFooter(t *testing.T) {
+	if l := defaultLayout.withFooter(0); l.bottom() != defaultLayout.bottom() {
+		t.Errorf("bottom() = %v without a footer, want %v", l.bottom(), defaultLayout.bottom())
+	}
+	l := defaultLayout.withFooter(10)
+	if l.bottom() >= defaultLayout.bottom() || l.top() != defaultLayout.top() {
+		t.Errorf("plot spans %v..%v, want it to start at %v and end above %v", l.top(), l.bottom(), defaultLayout.top(), defaultLayout.bottom())
+	}
+	small := newLayout(400, 200, 1).withTitle(MapInfo{Code: "KX7PQ", Creator: "genji"}).withFooter(10)
+	if small.chartHeight() <= 0 {
+		t.Errorf("chart height %v on a small canvas", small.chartHeight())
+	}
+}
+
+func TestRenderChartRequiredVotes(t *testing.T) {
+	useBackend(t, "go")
+	votes := map[string]int{"Hard": 3, "Hard +": 1}
+	for _, opts := range []Options{
+		{Format: FormatPNG, RequiredVotes: 10},
+		{Format: FormatPNG, RequiredVotes: 4},
+		{Format: FormatPNG, RequiredVotes: 10, Width: 200, Height: 100},
+		{Format: FormatPNG, RequiredVotes: 10, Orientation: OrientationHorizontal},
+		{Format: FormatPNG, RequiredVotes: 10, Type: ChartDonut, Width: 400, Height: 400},
+	} {
+		data, err := RenderChart(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderChart(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	if _, err := RenderChart(votes, Options{Format: FormatPNG, RequiredVotes: -1}); err == nil {
+		t.Error("RenderChart() with negative required votes succeeded")
+	}
+	if _, err := RenderChart(votes, Options{Format: FormatPNG, RequiredVotes: 10, VotesCast: -1}); err == nil {
+		t.Error("RenderChart() with negative votes cast succeeded")
+	}
+}
@@ -2,24 +2,16 @@ package chart
 
 import (
 	"bytes"
-	"image"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/kolesa-team/go-webp/encoder"
-	"github.com/kolesa-team/go-webp/webp"
-	"github.com/ungerik/go-cairo"
 )
 
-var webpEncoderOptions *encoder.Options
-
-func init() {
-	opts, err := encoder.NewLossyEncoderOptions(encoder.PresetDefault, 85)
-	if err != nil {
-		panic("failed to create webp encoder options: " + err.Error())
-	}
-	webpEncoderOptions = opts
-}
-
+// Layout constants are design-space values for the default
+// CanvasWidth x CanvasHeight canvas at 1x; see layout for how they scale.
 const (
 	CanvasWidth  = 1000
 	CanvasHeight = 500
@@ -31,48 +23,302 @@ const (
 	BarRadius    = 20
 )
 
-var (
-	BackgroundColor   = [3]float64{0.168, 0.176, 0.192} // #2b2d31
-	TextColor         = [3]float64{1.0, 1.0, 1.0}       // white
-	TextShadowColor   = [4]float64{0, 0, 0, 0.5}        // black with 50% opacity
+const (
 	TextShadowOffsetX = 1.5
 	TextShadowOffsetY = 1.5
 )
 
-func RenderChart(votes map[string]int) ([]byte, error) {
-	surface := cairo.NewSurface(cairo.FORMAT_ARGB32, CanvasWidth, CanvasHeight)
-	defer surface.Finish()
+// Options controls the output of RenderChart. Zero values select the
+// defaults: DefaultFormat(), CanvasWidth x CanvasHeight, a scale of 1 and
+// the DefaultThemeName theme.
+type Options struct {
+	Format Format
+	Width  int
+	Height int
+	Scale  float64
+	Theme  string
+	// Palette replaces the theme's bar colors with a named ramp.
+	Palette string
+	// Patterns overlays a per-level texture on every bar.
+	Patterns bool
+	// Colors overrides individual theme colors; see ParseColorOverrides.
+	Colors map[string]string
+	// DifficultyScale names the registered Scale the votes are cast on.
+	DifficultyScale string
+	// ShowMedian draws a marker at the median vote next to the average.
+	ShowMedian bool
+	// ShowIQR shades the interquartile range behind the bars.
+	ShowIQR bool
+	// ShowAgreement adds a badge with the Consensus class to the header.
+	ShowAgreement bool
+	// ShowConfidence shades the confidence interval of the average around
+	// the average line.
+	ShowConfidence bool
+	// Aggregation selects how the votes are combined into the average.
+	Aggregation Aggregation
+	// SubmittedDifficulty and OfficialDifficulty mark the level the map was
+	// submitted as and the level it was given on the level axis; either may
+	// be empty. The window widens to keep both in view. Donut and timeline
+	// charts ignore them.
+	SubmittedDifficulty string
+	OfficialDifficulty  string
+	// Map names the map the votes are for in a title block above the
+	// chart; the zero value draws none.
+	Map MapInfo
+	// RequiredVotes, when positive, adds a footer showing the votes cast
+	// against the number the playtest needs.
+	RequiredVotes int
+	// VotesCast is the number of votes the footer counts against
+	// RequiredVotes. Weighted votes should set it, since their total weight
+	// is not the number cast; zero takes the total of the tally. Timeline
+	// charts count their votes instead.
+	VotesCast int
+	// SampleSize is the effective number of votes the confidence interval
+	// is computed from. Weighted votes should set it, for example to
+	// EffectiveVotes of their ballots; zero takes the total of the tally.
+	SampleSize float64
+	// Type selects bars or a donut; the empty value is ChartBars. Donut
+	// charts show only the shares and the average, without the overlays.
+	// Grouped, comparison and timeline charts ignore it.
+	Type ChartType
+	// Orientation selects vertical or horizontal bars; the empty value is
+	// OrientationVertical. Grouped, comparison and timeline charts are
+	// always drawn vertically.
+	Orientation Orientation
+	// YAxis selects whether bars measure vote counts or shares of the
+	// votes; the empty value is YAxisCount. BarLabels selects the text
+	// above each bar; the empty value shows counts on a count axis and
+	// shares on a percent axis. Donut and timeline charts ignore both.
+	YAxis     YAxis
+	BarLabels BarLabel
+	// StabilityVotes is the number of latest votes the stability indicator
+	// of RenderTimeline measures; zero selects DefaultStabilityVotes.
+	StabilityVotes int
+
+	// groups is set by RenderGroups, previous by RenderComparison and
+	// timeline by RenderTimeline.
+	groups   []Group
+	previous Tally
+	timeline []TimedVote
+}
 
-	surface.SetSourceRGB(BackgroundColor[0], BackgroundColor[1], BackgroundColor[2])
-	surface.Rectangle(0, 0, CanvasWidth, CanvasHeight)
-	surface.Fill()
+func (o Options) withDefaults() Options {
+	if o.Format == "" {
+		o.Format = DefaultFormat()
+	}
+	if o.Width == 0 {
+		o.Width = CanvasWidth
+	}
+	if o.Height == 0 {
+		o.Height = CanvasHeight
+	}
+	if o.Scale == 0 {
+		o.Scale = 1
+	}
+	return o
+}
 
-	avg := CalculateWeightedAverage(votes)
-	avgLabel := AverageToLabel(avg)
-	minIdx, maxIdx := CalculateWindow(votes)
-	maxVotes := calculateMaxVotes(votes, minIdx, maxIdx)
+func RenderChart(votes map[string]int, opts Options) ([]byte, error) {
+	return RenderTally(TallyCounts(votes), opts)
+}
 
-	drawYAxisLines(surface, maxVotes, minIdx, maxIdx)
-	drawBars(surface, votes, minIdx, maxIdx, maxVotes)
-	drawXAxisLabels(surface, minIdx, maxIdx)
-	drawYAxis(surface, maxVotes)
-	drawVoteCounts(surface, votes, minIdx, maxIdx, maxVotes)
-	drawAverageLine(surface, avg, avgLabel, minIdx, maxIdx)
+// RenderTally renders a chart of weighted votes. Bars and vote labels show
+// the summed weight of each level.
+func RenderTally(votes Tally, opts Options) ([]byte, error) {
+	opts = opts.withDefaults()
+	if err := ValidateSize(opts.Width, opts.Height, opts.Scale); err != nil {
+		return nil, err
+	}
+	sc, err := LookupScale(opts.DifficultyScale)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Aggregation.Validate(sc); err != nil {
+		return nil, err
+	}
+	if _, err := LookupOrientation(string(opts.Orientation)); err != nil {
+		return nil, err
+	}
+	if _, err := LookupChartType(string(opts.Type)); err != nil {
+		return nil, err
+	}
+	if err := ValidateRequiredVotes(opts.RequiredVotes); err != nil {
+		return nil, err
+	}
+	if total := sumTally(votes); math.IsNaN(total) || math.IsInf(total, 0) {
+		return nil, errors.New("total vote weight must be finite")
+	}
+	if opts.VotesCast < 0 {
+		return nil, errors.New("votes cast must not be negative")
+	}
+	if opts.SampleSize < 0 || math.IsNaN(opts.SampleSize) || math.IsInf(opts.SampleSize, 0) {
+		return nil, errors.New("sample size must be a non-negative number")
+	}
+	if err := opts.validateMarkers(sc); err != nil {
+		return nil, err
+	}
+	if _, err := LookupYAxis(string(opts.YAxis)); err != nil {
+		return nil, err
+	}
+	if _, err := LookupBarLabel(string(opts.BarLabels)); err != nil {
+		return nil, err
+	}
+	th, err := LookupTheme(opts.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if sc != GenjiScale {
+		th = th.withScaleColors(sc)
+	}
+	palette, err := LookupPalette(opts.Palette)
+	if err != nil {
+		return nil, err
+	}
+	if palette != nil {
+		th = th.withPalette(palette, sc.Levels)
+	}
+	if len(opts.Colors) > 0 {
+		overrides, err := ParseColorOverrides(sc, opts.Colors)
+		if err != nil {
+			return nil, err
+		}
+		th = th.withColors(overrides)
+	}
+	l := newLayout(opts.Width, opts.Height, opts.Scale)
+	if opts.donut() {
+		l = donutLayout(l)
+	}
+	l = l.withTitle(opts.Map).withFooter(opts.RequiredVotes)
 
-	img := surfaceToImage(surface)
+	if render, ok := vectorRenderers[opts.Format]; ok {
+		return render(votes, l, th, sc, opts)
+	}
+
+	enc, ok := EncoderFor(opts.Format)
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %s", opts.Format)
+	}
+
+	canvas := newRasterCanvas(l.pixelSize())
+	defer canvas.Close()
+
+	drawChart(canvas, l, th, sc, votes, opts)
+
+	img := canvas.Image()
 
 	buf := bytes.NewBuffer(make([]byte, 0, 50*1024))
-	if err := webp.Encode(buf, img, webpEncoderOptions); err != nil {
+	if err := enc.Encode(buf, img); err != nil {
 		return nil, err
 	}
 
 	return buf.Bytes(), nil
 }
 
-func calculateMaxVotes(votes map[string]int, minIdx, maxIdx int) int {
-	maxVotes := 0
+// drawChart paints the complete chart onto c. It is shared by the
+// raster and vector output paths.
+func drawChart(c Canvas, l layout, th *Theme, sc *Scale, votes Tally, opts Options) {
+	switch {
+	case opts.previous != nil:
+		drawComparison(c, l, th, sc, opts.previous, votes, opts)
+	case opts.timeline != nil:
+		drawTimeline(c, l, th, sc, opts.timeline, opts)
+	case opts.donut():
+		drawDonut(c, l, th, sc, votes, opts)
+	case opts.Orientation == OrientationHorizontal && len(opts.groups) == 0:
+		drawHorizontalChart(c, l, th, sc, votes, opts)
+	default:
+		drawBarChart(c, l, th, sc, votes, opts)
+	}
+	drawTitle(c, l, th, opts.Map)
+	if opts.RequiredVotes > 0 {
+		drawProgress(c, l, th, VoteProgress(opts.votesCast(votes), opts.RequiredVotes))
+	}
+}
+
+// drawBarChart draws the votes as vertical bars, plain or stacked by group.
+func drawBarChart(c Canvas, l layout, th *Theme, sc *Scale, votes Tally, opts Options) {
+	setColor(c, th.Background)
+	c.Rectangle(0, 0, l.width, l.height)
+	c.Fill()
+
+	st := sc.SampleStats(votes, opts.SampleSize)
+	markers := opts.markers(th)
+	minIdx, maxIdx := sc.TallyWindow(votes, markerLevels(markers)...)
+	bars := newBarValues(votes, opts.YAxis, opts.BarLabels)
+	maxVotes := axisMax(sc, bars.values, minIdx, maxIdx, opts.YAxis)
+
+	drawYAxisLines(c, l, th, maxVotes)
+	if opts.ShowIQR {
+		drawBand(c, l, th.IQRBand, valueX(l, sc, st.Q1, minIdx, maxIdx), valueX(l, sc, st.Q3, minIdx, maxIdx))
+	}
+	if len(opts.groups) > 0 {
+		groups := opts.groups
+		if opts.YAxis == YAxisPercent {
+			groups = shareGroups(groups, bars.total)
+		}
+		drawStackedBars(c, l, th, sc, groups, bars.values, minIdx, maxIdx, maxVotes)
+	} else {
+		drawBars(c, l, th, sc, bars.values, minIdx, maxIdx, maxVotes, opts.Patterns)
+	}
+	drawXAxisLabels(c, l, th, sc, minIdx, maxIdx)
+	drawYAxis(c, l, th, maxVotes, opts.YAxis)
+	drawVoteCounts(c, l, th, sc, bars, minIdx, maxIdx, maxVotes)
+
+	avg := sc.aggregate(st, opts.Aggregation)
+	avgX := valueX(l, sc, avg, minIdx, maxIdx)
+	header := []headerLabel{
+		{text: opts.Aggregation.Mode.Label() + ": " + formatFloat(avg) + " (" + strings.ToUpper(sc.AverageToLabel(avg)) + ")", anchorX: avgX},
+	}
+	if opts.ShowMedian {
+		medianX := valueX(l, sc, st.Median, minIdx, maxIdx)
+		drawMedianLine(c, l, th, medianX)
+		header = append(header, headerLabel{text: "MEDIAN: " + formatFloat(st.Median) + " (" + strings.ToUpper(st.MedianLevel) + ")", anchorX: medianX})
+	}
+	// The interval is of the plain mean, so under any other average it is
+	// labeled over a mean line of its own rather than the average line.
+	var ci *span
+	ciX := avgX
+	if opts.ShowConfidence {
+		ci = &span{valueX(l, sc, st.CILow, minIdx, maxIdx), valueX(l, sc, st.CIHigh, minIdx, maxIdx)}
+		if !opts.Aggregation.isMean() {
+			ciX = valueX(l, sc, st.Mean, minIdx, maxIdx)
+		}
+		header = append(header, headerLabel{text: confidenceHeader(st, opts.Aggregation), anchorX: ciX})
+	}
+	inValley := sc.valleyAt(st, avg)
+	if inValley {
+		header = append(header, splitVoteWarning(th, st.Peaks, avgX))
+	}
+	for i, m := range markers {
+		drawMarkerLine(c, l, m.color, i, valueX(l, sc, sc.Midpoints[m.level], minIdx, maxIdx))
+	}
+	legend := markerLegend(markers)
+	if len(opts.groups) > 0 {
+		avgs := groupAverages(l, sc, opts.groups, opts.Aggregation, minIdx, maxIdx)
+		drawGroupAverageLines(c, l, th, opts.groups, avgs)
+		legend = append(groupLegend(th, opts.groups, avgs), legend...)
+	}
+	if len(legend) > 0 {
+		drawLegend(c, l, th, legend)
+	}
+	drawAverageLine(c, l, th, avgX, ci, inValley)
+	if ci != nil && !opts.Aggregation.isMean() {
+		drawMeanLine(c, l, th, ciX)
+	}
+
+	headerRight := l.right()
+	if opts.ShowAgreement {
+		headerRight = drawAgreementBadge(c, l, th, st) - l.px(headerLabelGap)
+	}
+	drawHeaderLabels(c, l, th, header, headerRight)
+}
+
+// calculateMaxVotes returns the top of the y axis: the largest visible
+// count, rounded up to a whole vote so the axis keeps integer ticks.
+func calculateMaxVotes(sc *Scale, votes Tally, minIdx, maxIdx int) int {
+	var maxVotes float64
 	for i := minIdx; i <= maxIdx; i++ {
-		level := DifficultyLevels[i]
+		level := sc.Levels[i]
 		if v := votes[level]; v > maxVotes {
 			maxVotes = v
 		}
@@ -80,83 +326,67 @@ func calculateMaxVotes(votes map[string]int, minIdx, maxIdx int) int {
 	if maxVotes == 0 {
 		return 1
 	}
-	return maxVotes
+	return int(math.Ceil(maxVotes))
 }
 
-func surfaceToImage(surface *cairo.Surface) *image.RGBA {
-	width := surface.GetWidth()
-	height := surface.GetHeight()
-	data := surface.GetData()
-
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-
-	pixelCount := width * height
-	for i := 0; i < pixelCount; i++ {
-		off := i * 4
-		img.Pix[off] = data[off+2]
-		img.Pix[off+1] = data[off+1]
-		img.Pix[off+2] = data[off]
-		img.Pix[off+3] = data[off+3]
-	}
-	return img
-}
-
-func drawTextWithShadow(surface *cairo.Surface, text string, x, y float64) {
-	surface.SetSourceRGBA(TextShadowColor[0], TextShadowColor[1], TextShadowColor[2], TextShadowColor[3])
-	surface.MoveTo(x+TextShadowOffsetX, y+TextShadowOffsetY)
-	surface.ShowText(text)
+func drawTextWithShadow(c Canvas, l layout, th *Theme, text string, x, y float64) {
+	setColor(c, th.TextShadow)
+	c.MoveTo(x+l.px(TextShadowOffsetX), y+l.px(TextShadowOffsetY))
+	c.ShowText(text)
 
 	// Draw main text
-	surface.SetSourceRGB(TextColor[0], TextColor[1], TextColor[2])
-	surface.MoveTo(x, y)
-	surface.ShowText(text)
+	setColor(c, th.Text)
+	c.MoveTo(x, y)
+	c.ShowText(text)
 }
 
 const (
 	ShadowOffsetX = 3
 	ShadowOffsetY = 3
-	ShadowAlpha   = 0.3
 )
 
-func drawBars(surface *cairo.Surface, votes map[string]int, minIdx, maxIdx, maxVotes int) {
-	chartWidth := float64(CanvasWidth - LeftMargin - RightMargin)
-	chartHeight := float64(CanvasHeight - TopMargin - BottomMargin)
+func drawBars(c Canvas, l layout, th *Theme, sc *Scale, votes Tally, minIdx, maxIdx, maxVotes int, patterns bool) {
+	chartHeight := l.chartHeight()
 	numBars := maxIdx - minIdx + 1
-	barWidth := (chartWidth - float64(numBars-1)*BarGap) / float64(numBars)
+	barWidth := l.barWidth(numBars)
 
-	surface.SetSourceRGBA(0, 0, 0, ShadowAlpha)
+	setColor(c, th.BarShadow)
 	for i := minIdx; i <= maxIdx; i++ {
-		level := DifficultyLevels[i]
+		level := sc.Levels[i]
 		voteCount := votes[level]
 		if voteCount == 0 {
 			continue
 		}
 
-		x := float64(LeftMargin) + float64(i-minIdx)*(barWidth+BarGap)
-		barHeight := (float64(voteCount) / float64(maxVotes)) * chartHeight
-		y := float64(TopMargin) + chartHeight - barHeight
+		x := l.barX(i-minIdx, numBars)
+		barHeight := (voteCount / float64(maxVotes)) * chartHeight
+		y := l.top() + chartHeight - barHeight
 
-		drawRoundedTopRect(surface, x+ShadowOffsetX, y+ShadowOffsetY, barWidth, barHeight, BarRadius)
-		surface.Fill()
+		drawRoundedTopRect(c, x+l.px(ShadowOffsetX), y+l.px(ShadowOffsetY), barWidth, barHeight, l.px(BarRadius))
+		c.Fill()
 	}
 
 	for i := minIdx; i <= maxIdx; i++ {
-		level := DifficultyLevels[i]
+		level := sc.Levels[i]
 		voteCount := votes[level]
 
-		x := float64(LeftMargin) + float64(i-minIdx)*(barWidth+BarGap)
-		barHeight := (float64(voteCount) / float64(maxVotes)) * chartHeight
-		y := float64(TopMargin) + chartHeight - barHeight
+		x := l.barX(i-minIdx, numBars)
+		barHeight := (voteCount / float64(maxVotes)) * chartHeight
+		y := l.top() + chartHeight - barHeight
 
-		r, g, b := ParseHexColor(DifficultyColors[level])
-		surface.SetSourceRGB(float64(r)/255, float64(g)/255, float64(b)/255)
+		color := th.barColor(level)
+		setColor(c, color)
 
-		drawRoundedTopRect(surface, x, y, barWidth, barHeight, BarRadius)
-		surface.Fill()
+		drawRoundedTopRect(c, x, y, barWidth, barHeight, l.px(BarRadius))
+		c.Fill()
+
+		if patterns {
+			drawBarPattern(c, l, PatternForLevel(i), color, x, y, barWidth, barHeight, l.px(BarRadius))
+		}
 	}
 }
 
-func drawRoundedTopRect(surface *cairo.Surface, x, y, w, h, r float64) {
+func drawRoundedTopRect(c Canvas, x, y, w, h, r float64) {
 	if h < r {
 		r = h
 	}
@@ -164,66 +394,79 @@ func drawRoundedTopRect(surface *cairo.Surface, x, y, w, h, r float64) {
 		r = 0
 	}
 
-	surface.MoveTo(x, y+h)
-	surface.LineTo(x, y+r)
-	surface.Arc(x+r, y+r, r, 3.14159, 1.5*3.14159)
-	surface.LineTo(x+w-r, y)
-	surface.Arc(x+w-r, y+r, r, 1.5*3.14159, 2*3.14159)
-	surface.LineTo(x+w, y+h)
-	surface.ClosePath()
+	c.MoveTo(x, y+h)
+	c.LineTo(x, y+r)
+	c.Arc(x+r, y+r, r, 3.14159, 1.5*3.14159)
+	c.LineTo(x+w-r, y)
+	c.Arc(x+w-r, y+r, r, 1.5*3.14159, 2*3.14159)
+	c.LineTo(x+w, y+h)
+	c.ClosePath()
 }
 
-func drawXAxisLabels(surface *cairo.Surface, minIdx, maxIdx int) {
-	surface.SelectFontFace("Bank Sans EF CY", cairo.FONT_SLANT_NORMAL, cairo.FONT_WEIGHT_NORMAL)
-	surface.SetFontSize(16)
+func drawRoundedRect(c Canvas, x, y, w, h, r float64) {
+	r = math.Min(r, math.Min(w, h)/2)
+
+	c.MoveTo(x+r, y)
+	c.LineTo(x+w-r, y)
+	c.Arc(x+w-r, y+r, r, 1.5*math.Pi, 2*math.Pi)
+	c.LineTo(x+w, y+h-r)
+	c.Arc(x+w-r, y+h-r, r, 0, 0.5*math.Pi)
+	c.LineTo(x+r, y+h)
+	c.Arc(x+r, y+h-r, r, 0.5*math.Pi, math.Pi)
+	c.LineTo(x, y+r)
+	c.Arc(x+r, y+r, r, math.Pi, 1.5*math.Pi)
+	c.ClosePath()
+}
+
+func drawXAxisLabels(c Canvas, l layout, th *Theme, sc *Scale, minIdx, maxIdx int) {
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(16))
 
-	chartWidth := float64(CanvasWidth - LeftMargin - RightMargin)
 	numBars := maxIdx - minIdx + 1
-	barWidth := (chartWidth - float64(numBars-1)*BarGap) / float64(numBars)
+	barWidth := l.barWidth(numBars)
 
 	for i := minIdx; i <= maxIdx; i++ {
-		level := DifficultyLevels[i]
-		x := float64(LeftMargin) + float64(i-minIdx)*(barWidth+BarGap) + barWidth/2
+		level := sc.Levels[i]
+		x := l.barX(i-minIdx, numBars) + barWidth/2
 
 		upperLevel := strings.ToUpper(level)
-		extents := surface.TextExtents(upperLevel)
+		extents := c.TextExtents(upperLevel)
 		textX := x - extents.Width/2
 
-		drawTextWithShadow(surface, upperLevel, textX, float64(CanvasHeight-BottomMargin+30))
+		drawTextWithShadow(c, l, th, upperLevel, textX, l.bottom()+l.px(30))
 	}
 }
 
-func drawYAxisLines(surface *cairo.Surface, maxVotes, minIdx, maxIdx int) {
-	chartWidth := float64(CanvasWidth - LeftMargin - RightMargin)
-	chartHeight := float64(CanvasHeight - TopMargin - BottomMargin)
+func drawYAxisLines(c Canvas, l layout, th *Theme, maxVotes int) {
+	chartHeight := l.chartHeight()
 
-	surface.SetSourceRGBA(1, 1, 1, 0.15)
-	surface.SetLineWidth(1)
+	setColor(c, th.GridLine)
+	c.SetLineWidth(l.px(1))
 
 	for i := 0; i <= 4; i++ {
 		value := (maxVotes * i) / 4
-		y := float64(TopMargin) + chartHeight - (float64(value)/float64(maxVotes))*chartHeight
+		y := l.top() + chartHeight - (float64(value)/float64(maxVotes))*chartHeight
 
-		surface.MoveTo(float64(LeftMargin), y)
-		surface.LineTo(float64(LeftMargin)+chartWidth, y)
-		surface.Stroke()
+		c.MoveTo(l.left(), y)
+		c.LineTo(l.right(), y)
+		c.Stroke()
 	}
 }
 
-func drawYAxis(surface *cairo.Surface, maxVotes int) {
-	surface.SelectFontFace("Bank Sans EF CY", cairo.FONT_SLANT_NORMAL, cairo.FONT_WEIGHT_NORMAL)
-	surface.SetFontSize(12)
+func drawYAxis(c Canvas, l layout, th *Theme, maxVotes int, axis YAxis) {
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(12))
 
-	chartHeight := float64(CanvasHeight - TopMargin - BottomMargin)
+	chartHeight := l.chartHeight()
 
 	for i := 0; i <= 4; i++ {
 		value := (maxVotes * i) / 4
-		y := float64(TopMargin) + chartHeight - (float64(value)/float64(maxVotes))*chartHeight
+		y := l.top() + chartHeight - (float64(value)/float64(maxVotes))*chartHeight
 
-		label := formatInt(value)
+		label := axis.tickLabel(value)
 
-		extents := surface.TextExtents(label)
-		drawTextWithShadow(surface, label, float64(LeftMargin)-extents.Width-10, y+extents.Height/2)
+		extents := c.TextExtents(label)
+		drawTextWithShadow(c, l, th, label, l.left()-extents.Width-l.px(10), y+extents.Height/2)
 	}
 }
 
@@ -239,68 +482,289 @@ func formatInt(n int) string {
 	return result
 }
 
-func drawVoteCounts(surface *cairo.Surface, votes map[string]int, minIdx, maxIdx, maxVotes int) {
-	surface.SelectFontFace("Bank Sans EF CY", cairo.FONT_SLANT_NORMAL, cairo.FONT_WEIGHT_BOLD)
-	surface.SetFontSize(13)
+// formatCount formats a vote count, which may be fractional when votes are
+// weighted, with at most two decimals and no trailing zeros.
+func formatCount(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
 
-	chartWidth := float64(CanvasWidth - LeftMargin - RightMargin)
-	chartHeight := float64(CanvasHeight - TopMargin - BottomMargin)
+func drawVoteCounts(c Canvas, l layout, th *Theme, sc *Scale, bars barValues, minIdx, maxIdx, maxVotes int) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(13))
+
+	chartHeight := l.chartHeight()
 	numBars := maxIdx - minIdx + 1
-	barWidth := (chartWidth - float64(numBars-1)*BarGap) / float64(numBars)
+	barWidth := l.barWidth(numBars)
 
 	for i := minIdx; i <= maxIdx; i++ {
-		level := DifficultyLevels[i]
-		voteCount := votes[level]
-		if voteCount == 0 {
+		level := sc.Levels[i]
+		value := bars.values[level]
+		if value == 0 {
 			continue
 		}
 
-		x := float64(LeftMargin) + float64(i-minIdx)*(barWidth+BarGap) + barWidth/2
-		barHeight := (float64(voteCount) / float64(maxVotes)) * chartHeight
-		y := float64(TopMargin) + chartHeight - barHeight - 15
+		x := l.barX(i-minIdx, numBars) + barWidth/2
+		barHeight := (value / float64(maxVotes)) * chartHeight
+		y := l.top() + chartHeight - barHeight - l.px(15)
 
-		label := formatInt(voteCount)
-		extents := surface.TextExtents(label)
-		drawTextWithShadow(surface, label, x-extents.Width/2, y)
+		label := bars.label(level)
+		extents := c.TextExtents(label)
+		drawTextWithShadow(c, l, th, label, x-extents.Width/2, y)
 	}
 }
 
-func drawAverageLine(surface *cairo.Surface, avg float64, avgLabel string, minIdx, maxIdx int) {
-	chartWidth := float64(CanvasWidth - LeftMargin - RightMargin)
-	chartHeight := float64(CanvasHeight - TopMargin - BottomMargin)
+// valueX maps a value on the scale's numeric axis, such as an average, to
+// the x coordinate of the plot area showing levels minIdx..maxIdx.
+func valueX(l layout, sc *Scale, v float64, minIdx, maxIdx int) float64 {
+	minValue := sc.Ranges[sc.Levels[minIdx]].Lower
+	maxValue := sc.Ranges[sc.Levels[maxIdx]].Upper
+
+	xRatio := (v - minValue) / (maxValue - minValue)
+	return l.left() + xRatio*l.chartWidth()
+}
+
+// span is a horizontal extent of the plot in output pixels.
+type span struct {
+	x0, x1 float64
+}
 
-	minValue := DifficultyRanges[DifficultyLevels[minIdx]].Lower
-	maxValue := DifficultyRanges[DifficultyLevels[maxIdx]].Upper
+// drawAverageLine draws the dashed average line, in the warning color when
+// the average sits in a valley between peaks. A non-nil ci is shaded behind
+// the line as the confidence interval of the mean.
+func drawAverageLine(c Canvas, l layout, th *Theme, x float64, ci *span, inValley bool) {
+	if ci != nil {
+		drawBand(c, l, th.ConfidenceBand, ci.x0, ci.x1)
+	}
 
-	xRatio := (avg - minValue) / (maxValue - minValue)
-	x := float64(LeftMargin) + xRatio*chartWidth
+	if inValley {
+		setColor(c, th.Warning)
+	} else {
+		setColor(c, th.AverageLine)
+	}
+	c.SetLineWidth(l.px(2))
+	dashes := []float64{l.px(8), l.px(5)}
+	c.SetDash(dashes, 0)
+	c.MoveTo(x, l.top())
+	c.LineTo(x, l.bottom())
+	c.Stroke()
+	c.SetDash(nil, 0)
+}
 
-	surface.SetSourceRGB(1, 1, 1)
-	surface.SetLineWidth(2)
-	dashes := []float64{8, 5}
-	surface.SetDash(dashes, len(dashes), 0)
-	surface.MoveTo(x, float64(TopMargin))
-	surface.LineTo(x, float64(TopMargin)+chartHeight)
-	surface.Stroke()
+// drawMedianLine draws a solid line with a pointer on the x axis, so it can
+// be told apart from the dashed average line.
+func drawMedianLine(c Canvas, l layout, th *Theme, x float64) {
+	setColor(c, th.MedianLine)
+	c.SetLineWidth(l.px(2))
+	c.MoveTo(x, l.top())
+	c.LineTo(x, l.bottom())
+	c.Stroke()
+
+	size := l.px(7)
+	c.MoveTo(x, l.bottom()-size)
+	c.LineTo(x+size, l.bottom()+size/2)
+	c.LineTo(x-size, l.bottom()+size/2)
+	c.ClosePath()
+	c.Fill()
+}
 
-	// Draw label with shadow
-	surface.SelectFontFace("Bank Sans EF CY", cairo.FONT_SLANT_NORMAL, cairo.FONT_WEIGHT_BOLD)
-	surface.SetFontSize(13)
+// drawMeanLine draws a thin solid line at the plain mean, marking the
+// center of the confidence band when the average line is another average.
+func drawMeanLine(c Canvas, l layout, th *Theme, x float64) {
+	setColor(c, th.AverageLine)
+	c.SetLineWidth(l.px(1))
+	c.MoveTo(x, l.top())
+	c.LineTo(x, l.bottom())
+	c.Stroke()
+}
 
-	labelText := "AVG: " + formatFloat(avg) + " (" + strings.ToUpper(avgLabel) + ")"
-	extents := surface.TextExtents(labelText)
+// drawBand shades the plot between x0 and x1, clipped to the plot area and
+// widened to stay visible when the two nearly coincide.
+func drawBand(c Canvas, l layout, col Color, x0, x1 float64) {
+	if w := l.px(2); x1-x0 < w {
+		mid := (x0 + x1) / 2
+		x0, x1 = mid-w/2, mid+w/2
+	}
+	x0, x1 = math.Max(x0, l.left()), math.Min(x1, l.right())
+	if x1 <= x0 {
+		return
+	}
+	setColor(c, col)
+	c.Rectangle(x0, l.top(), x1-x0, l.chartHeight())
+	c.Fill()
+}
 
-	labelX := x - extents.Width/2
-	labelY := float64(TopMargin) - 45
+// confidenceText describes the confidence interval of the mean by the
+// levels at its ends.
+func confidenceText(st Stats) string {
+	text := formatInt(int(math.Round(ConfidenceLevel*100))) + "% CI: " + strings.ToUpper(st.CILowLevel)
+	if st.CIHighLevel != st.CILowLevel {
+		text += " TO " + strings.ToUpper(st.CIHighLevel)
+	}
+	return text
+}
 
-	if labelX < float64(LeftMargin) {
-		labelX = float64(LeftMargin)
+// confidenceHeader is the header label text for confidenceText. When a is
+// not the mean, the mean itself is named too, since the header's average is
+// then a different value.
+func confidenceHeader(st Stats, a Aggregation) string {
+	if a.isMean() {
+		return confidenceText(st)
 	}
-	if labelX+extents.Width > float64(CanvasWidth-RightMargin) {
-		labelX = float64(CanvasWidth-RightMargin) - extents.Width
+	return "MEAN: " + formatFloat(st.Mean) + ", " + confidenceText(st)
+}
+
+// headerLabel is a caption drawn above the plot area, centered over the
+// x coordinate it describes where space allows. Labels with badge set are
+// drawn as a pill filled with fill.
+type headerLabel struct {
+	text    string
+	anchorX float64
+	badge   bool
+	fill    Color
+}
+
+const headerLabelGap = 24
+
+// drawHeaderLabels lays labels out on the header row between the left edge of
+// the plot and maxX.
+func drawHeaderLabels(c Canvas, l layout, th *Theme, labels []headerLabel, maxX float64) {
+	widths := make([]float64, len(labels))
+	anchors := make([]float64, len(labels))
+	for i, label := range labels {
+		if label.badge {
+			widths[i], _ = badgeSize(c, l, label.text)
+		} else {
+			selectHeaderFont(c, l)
+			widths[i] = c.TextExtents(label.text).Width
+		}
+		anchors[i] = label.anchorX
 	}
 
-	drawTextWithShadow(surface, labelText, labelX, labelY)
+	xs := placeLabels(widths, anchors, l.left(), maxX, l.px(headerLabelGap))
+	for i, label := range labels {
+		if label.badge {
+			drawHeaderBadge(c, l, label.fill, label.text, xs[i])
+			continue
+		}
+		selectHeaderFont(c, l)
+		drawTextWithShadow(c, l, th, label.text, xs[i], headerBaseline(l))
+	}
+}
+
+func selectHeaderFont(c Canvas, l layout) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(13))
+}
+
+func headerBaseline(l layout) float64 {
+	return l.top() - l.px(45)
+}
+
+const (
+	badgeHeight   = 22
+	badgePaddingX = 10
+)
+
+// drawAgreementBadge draws a pill with the consensus class and agreement
+// score at the right end of the header row and returns its left edge.
+func drawAgreementBadge(c Canvas, l layout, th *Theme, st Stats) float64 {
+	text := strings.ToUpper(string(st.Consensus)) + " " + formatFloat(st.Agreement)
+	w, _ := badgeSize(c, l, text)
+	x := l.right() - w
+	drawHeaderBadge(c, l, th.ConsensusColors[st.Consensus], text, x)
+	return x
+}
+
+// splitVoteWarning is the header badge flagging an average that sits in the
+// valley between peaks. It is anchored on the average line so it lands next
+// to the average label.
+func splitVoteWarning(th *Theme, peaks []string, avgX float64) headerLabel {
+	upper := make([]string, len(peaks))
+	for i, p := range peaks {
+		upper[i] = strings.ToUpper(p)
+	}
+	return headerLabel{
+		text:    "! SPLIT VOTE: PEAKS AT " + joinWords(upper),
+		anchorX: avgX,
+		badge:   true,
+		fill:    th.Warning,
+	}
+}
+
+// joinWords joins items as "A", "A AND B" or "A, B AND C".
+func joinWords(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " AND " + items[len(items)-1]
+}
+
+// badgeSize measures the pill drawBadge would draw for text.
+func badgeSize(c Canvas, l layout, text string) (w, h float64) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(l.px(12))
+	return c.TextExtents(text).Width + 2*l.px(badgePaddingX), l.px(badgeHeight)
+}
+
+// drawHeaderBadge draws a badge vertically centered on the header row.
+func drawHeaderBadge(c Canvas, l layout, fill Color, text string, x float64) {
+	h := l.px(badgeHeight)
+	drawBadge(c, l, fill, text, x, headerBaseline(l)-l.px(5)-h/2)
+}
+
+// drawBadge draws text on a pill filled with fill, with its top-left corner
+// at (x, y). The text is dark or white, whichever contrasts with fill.
+func drawBadge(c Canvas, l layout, fill Color, text string, x, y float64) {
+	w, h := badgeSize(c, l, text)
+	extents := c.TextExtents(text)
+
+	setColor(c, fill)
+	drawRoundedRect(c, x, y, w, h, h/2)
+	c.Fill()
+
+	if fill.luminance() > 0.5 {
+		setColor(c, hexColor("#1e1f22"))
+	} else {
+		setColor(c, RGB(1, 1, 1))
+	}
+	c.MoveTo(x+l.px(badgePaddingX), y+h/2-extents.YBearing-extents.Height/2)
+	c.ShowText(text)
+}
+
+// placeLabels positions labels of the given widths on one row between minX
+// and maxX. Each label is centered on its anchor, then pushed sideways just
+// enough to keep gap between neighbors. It returns the left edge of every
+// label, in input order.
+func placeLabels(widths, anchors []float64, minX, maxX, gap float64) []float64 {
+	order := make([]int, len(widths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return anchors[order[a]] < anchors[order[b]] })
+
+	xs := make([]float64, len(widths))
+	for _, i := range order {
+		xs[i] = math.Max(minX, math.Min(anchors[i]-widths[i]/2, maxX-widths[i]))
+	}
+
+	// Push right past the previous label, then back left from maxX.
+	for k := 1; k < len(order); k++ {
+		prev, cur := order[k-1], order[k]
+		xs[cur] = math.Max(xs[cur], xs[prev]+widths[prev]+gap)
+	}
+	for k := len(order) - 1; k >= 0; k-- {
+		cur := order[k]
+		limit := maxX - widths[cur]
+		if k < len(order)-1 {
+			next := order[k+1]
+			limit = xs[next] - gap - widths[cur]
+		}
+		xs[cur] = math.Max(minX, math.Min(xs[cur], limit))
+	}
+	return xs
 }
 
 func formatFloat(f float64) string {
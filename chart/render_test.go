@@ -2,17 +2,26 @@
 package chart
 
 import (
+	"strings"
 	"testing"
 )
 
+func requireFormat(t *testing.T, f Format) {
+	t.Helper()
+	if f.ContentType() == "" {
+		t.Skipf("%s output is not available in this build", f)
+	}
+}
+
 func TestRenderChart(t *testing.T) {
+	requireFormat(t, FormatWebP)
 	votes := map[string]int{
 		"Medium":   10,
 		"Medium +": 5,
 		"Hard -":   3,
 	}
 
-	imgData, err := RenderChart(votes)
+	imgData, err := RenderChart(votes, Options{Format: FormatWebP})
 	if err != nil {
 		t.Fatalf("RenderChart error: %v", err)
 	}
@@ -25,3 +34,124 @@ func TestRenderChart(t *testing.T) {
 		t.Error("expected WebP format (RIFF header)")
 	}
 }
+
+func TestRenderChartPNG(t *testing.T) {
+	votes := map[string]int{"Hard": 4, "Hard +": 2}
+
+	imgData, err := RenderChart(votes, Options{Format: FormatPNG})
+	if err != nil {
+		t.Fatalf("RenderChart error: %v", err)
+	}
+	if len(imgData) < 8 || string(imgData[1:4]) != "PNG" {
+		t.Error("expected PNG format (PNG signature)")
+	}
+}
+
+func TestRenderChartUnsupportedFormat(t *testing.T) {
+	if _, err := RenderChart(map[string]int{"Hard": 1}, Options{Format: Format("gif")}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestRenderChartSVG(t *testing.T) {
+	requireFormat(t, FormatSVG)
+	votes := map[string]int{"Hard": 4, "Hard +": 2}
+
+	data, err := RenderChart(votes, Options{Format: FormatSVG})
+	if err != nil {
+		t.Fatalf("RenderChart error: %v", err)
+	}
+	if !strings.Contains(string(data), "<svg") {
+		t.Error("expected SVG document")
+	}
+}
+
+func TestPlaceLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		widths  []float64
+		anchors []float64
+		want    []float64
+	}{
+		{"centered", []float64{100}, []float64{500}, []float64{450}},
+		{"clamped left", []float64{100}, []float64{20}, []float64{0}},
+		{"clamped right", []float64{100}, []float64{990}, []float64{900}},
+		{"apart", []float64{100, 100}, []float64{200, 700}, []float64{150, 650}},
+		{"pushed right", []float64{100, 100}, []float64{400, 420}, []float64{350, 460}},
+		{"input order kept", []float64{100, 100}, []float64{420, 400}, []float64{460, 350}},
+		{"pushed back from edge", []float64{100, 100}, []float64{940, 950}, []float64{790, 900}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := placeLabels(tt.widths, tt.anchors, 0, 1000, 10)
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("placeLabels = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRenderChartStatsOverlays(t *testing.T) {
+	useBackend(t, "go")
+	votes := map[string]int{"Easy": 3, "Hard": 1, "Extreme": 3}
+	for _, opts := range []Options{
+		{Format: FormatPNG, ShowMedian: true},
+		{Format: FormatPNG, ShowIQR: true},
+		{Format: FormatPNG, ShowAgreement: true},
+		{Format: FormatPNG, ShowAgreement: true, ShowMedian: true, Width: 400, Height: 200},
+		{Format: FormatPNG, ShowMedian: true, ShowIQR: true, ShowAgreement: true, Theme: "light"},
+		{Format: FormatPNG, ShowConfidence: true},
+		{Format: FormatPNG, Aggregation: Aggregation{Mode: AggregateTrimmed}},
+		{Format: FormatPNG, Aggregation: Aggregation{Mode: AggregateBayesian, Prior: "Hard"}, ShowConfidence: true},
+		{Format: FormatPNG, ShowConfidence: true, ShowMedian: true, ShowIQR: true, Theme: "light"},
+	} {
+		data, err := RenderChart(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderChart(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+}
+
+func TestRenderTallyFractional(t *testing.T) {
+	useBackend(t, "go")
+	data, err := RenderTally(Tally{"Medium": 2.5, "Hard": 0.75}, Options{Format: FormatPNG})
+	if err != nil || len(data) == 0 {
+		t.Fatalf("RenderTally = %d bytes, %v", len(data), err)
+	}
+
+	if _, err := RenderTally(Tally{"Medium": 1e308, "Hard": 1e308}, Options{Format: FormatPNG}); err == nil {
+		t.Error("RenderTally with an infinite total succeeded")
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0"},
+		{3, "3"},
+		{120, "120"},
+		{2.5, "2.5"},
+		{1.333, "1.33"},
+		{0.75, "0.75"},
+		{2.999, "3"},
+	}
+	for _, tt := range tests {
+		if got := formatCount(tt.in); got != tt.want {
+			t.Errorf("formatCount(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateMaxVotesRoundsUp(t *testing.T) {
+	if got := calculateMaxVotes(GenjiScale, Tally{"Hard": 2.25, "Hard +": 1}, 6, 10); got != 3 {
+		t.Errorf("calculateMaxVotes() = %d, want 3", got)
+	}
+	if got := calculateMaxVotes(GenjiScale, Tally{"Hard": 0.5}, 6, 10); got != 1 {
+		t.Errorf("calculateMaxVotes() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,165 @@
+package chart
+
+import (
+	"errors"
+	"strings"
+)
+
+// ReportMap is one map's playtest results within a PDF report.
+type ReportMap struct {
+	Code    string
+	Title   string
+	Creator string
+	Votes   map[string]int
+}
+
+const (
+	ReportHeaderHeight = 90
+	ReportPageWidth    = CanvasWidth
+	ReportPageHeight   = CanvasHeight + ReportHeaderHeight
+
+	reportMargin    = 45
+	reportRowHeight = 30
+	reportTableTop  = ReportHeaderHeight + 20
+)
+
+var reportColumns = []struct {
+	title string
+	width float64
+}{
+	{"CODE", 120},
+	{"TITLE", 280},
+	{"CREATOR", 180},
+	{"VOTES", 80},
+	{"AVG", 80},
+	{"DIFFICULTY", 170},
+}
+
+// ErrReportUnsupported is returned by RenderReport in builds without cairo,
+// which is the only backend able to produce PDF documents.
+var ErrReportUnsupported = errors.New("PDF reports require the cairo backend")
+
+// reportTheme is used for every page of a PDF report.
+var reportTheme = DarkTheme
+
+func drawReportBackground(c Canvas) {
+	setColor(c, reportTheme.Background)
+	c.Rectangle(0, 0, ReportPageWidth, ReportPageHeight)
+	c.Fill()
+}
+
+// reportSummaryPages splits maps into the rows shown on each summary page.
+func reportSummaryPages(maps []ReportMap) [][]ReportMap {
+	rowsPerPage := (ReportPageHeight - reportTableTop - reportMargin) / reportRowHeight
+
+	var pages [][]ReportMap
+	for start := 0; start < len(maps); start += rowsPerPage {
+		end := start + rowsPerPage
+		if end > len(maps) {
+			end = len(maps)
+		}
+		pages = append(pages, maps[start:end])
+	}
+	return pages
+}
+
+func drawReportSummaryPage(c Canvas, rows []ReportMap, totalMaps int) {
+	drawReportBackground(c)
+	drawReportHeading(c, "PLAYTEST REPORT", formatInt(totalMaps)+" maps")
+	drawReportTableHeader(c, reportTableTop)
+	for i, m := range rows {
+		drawReportTableRow(c, m, reportTableTop+float64(i+1)*reportRowHeight)
+	}
+}
+
+func drawReportTableHeader(c Canvas, y float64) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(13)
+
+	x := float64(reportMargin)
+	for _, col := range reportColumns {
+		drawTextWithShadow(c, defaultLayout, reportTheme, col.title, x, y)
+		x += col.width
+	}
+
+	setColor(c, reportTheme.GridLine)
+	c.SetLineWidth(1)
+	c.MoveTo(reportMargin, y+10)
+	c.LineTo(ReportPageWidth-reportMargin, y+10)
+	c.Stroke()
+}
+
+func drawReportTableRow(c Canvas, m ReportMap, y float64) {
+	c.SelectFont(FontInter, false)
+	c.SetFontSize(13)
+
+	avg := CalculateWeightedAverage(m.Votes)
+	total := 0
+	for _, count := range m.Votes {
+		total += count
+	}
+
+	cells := []string{
+		m.Code,
+		m.Title,
+		m.Creator,
+		formatInt(total),
+		formatFloat(avg),
+		strings.ToUpper(AverageToLabel(avg)),
+	}
+
+	x := float64(reportMargin)
+	for i, col := range reportColumns {
+		text := fitText(c, cells[i], col.width-15)
+		drawTextWithShadow(c, defaultLayout, reportTheme, text, x, y)
+		x += col.width
+	}
+}
+
+func drawReportChartPage(c Canvas, m ReportMap) {
+	drawReportBackground(c)
+
+	title := m.Code
+	if m.Title != "" {
+		title += " - " + m.Title
+	}
+	subtitle := ""
+	if m.Creator != "" {
+		subtitle = "by " + m.Creator
+	}
+	drawReportHeading(c, title, subtitle)
+
+	c.Save()
+	c.Translate(0, ReportHeaderHeight)
+	drawChart(c, defaultLayout, reportTheme, GenjiScale, TallyCounts(m.Votes), Options{})
+	c.Restore()
+}
+
+func drawReportHeading(c Canvas, title, subtitle string) {
+	c.SelectFont(FontBankSans, true)
+	c.SetFontSize(26)
+	drawTextWithShadow(c, defaultLayout, reportTheme, fitText(c, title, ReportPageWidth-2*reportMargin), reportMargin, 45)
+
+	if subtitle != "" {
+		c.SelectFont(FontInter, false)
+		c.SetFontSize(14)
+		drawTextWithShadow(c, defaultLayout, reportTheme, fitText(c, subtitle, ReportPageWidth-2*reportMargin), reportMargin, 72)
+	}
+}
+
+// fitText shortens text with an ellipsis until it fits within maxWidth using
+// the canvas's current font.
+func fitText(c Canvas, text string, maxWidth float64) string {
+	if c.TextExtents(text).Width <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "…"
+		if c.TextExtents(candidate).Width <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
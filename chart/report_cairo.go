@@ -0,0 +1,24 @@
+//go:build !purego
+
+package chart
+
+import "github.com/ungerik/go-cairo"
+
+// RenderReport produces a PDF with a summary table of every map followed by
+// one chart page per map.
+func RenderReport(maps []ReportMap) ([]byte, error) {
+	newSurface := func(path string) *cairo.Surface {
+		return cairo.NewPDFSurface(path, ReportPageWidth, ReportPageHeight, cairo.PDF_VERSION_1_5)
+	}
+	return renderVector("report-*.pdf", newSurface, func(surface *cairo.Surface) {
+		c := newCairoCanvas(surface)
+		for _, rows := range reportSummaryPages(maps) {
+			drawReportSummaryPage(c, rows, len(maps))
+			surface.ShowPage()
+		}
+		for _, m := range maps {
+			drawReportChartPage(c, m)
+			surface.ShowPage()
+		}
+	})
+}
@@ -0,0 +1,8 @@
+//go:build purego
+
+package chart
+
+// RenderReport is unavailable without cairo; see ErrReportUnsupported.
+func RenderReport(maps []ReportMap) ([]byte, error) {
+	return nil, ErrReportUnsupported
+}
@@ -0,0 +1,25 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRenderReport(t *testing.T) {
+	maps := []ReportMap{
+		{Code: "ABC12", Title: "Temple", Creator: "genji", Votes: map[string]int{"Hard": 3, "Hard +": 2}},
+		{Code: "XYZ99", Votes: map[string]int{"Medium": 5}},
+	}
+
+	data, err := RenderReport(maps)
+	if errors.Is(err, ErrReportUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("RenderReport error: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Error("expected PDF document")
+	}
+}
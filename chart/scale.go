@@ -0,0 +1,200 @@
+package chart
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Scale bundles an ordered set of difficulty levels with the midpoint used
+// for averaging, the range each average maps back to, and the default bar
+// color of every level. Registered scales are shared between requests and
+// must not be modified.
+type Scale struct {
+	Name      string
+	Levels    []string
+	Midpoints map[string]float64
+	Ranges    map[string]DifficultyRange
+	Colors    map[string]Color
+
+	index map[string]int
+}
+
+// NewScale builds a scale from its parts. Levels are ordered from easiest to
+// hardest.
+func NewScale(name string, levels []string, midpoints map[string]float64, ranges map[string]DifficultyRange, colors map[string]Color) *Scale {
+	s := &Scale{
+		Name:      name,
+		Levels:    levels,
+		Midpoints: midpoints,
+		Ranges:    ranges,
+		Colors:    colors,
+		index:     make(map[string]int, len(levels)),
+	}
+	for i, level := range levels {
+		s.index[level] = i
+	}
+	return s
+}
+
+const DefaultScaleName = "genji"
+
+// GenjiScale is the built-in Genji parkour scale described by the
+// Difficulty* variables.
+var GenjiScale = NewScale(DefaultScaleName, DifficultyLevels, DifficultyMidpoints, DifficultyRanges, hexColors(DifficultyColors))
+
+var scales = map[string]*Scale{
+	GenjiScale.Name: GenjiScale,
+}
+
+// LookupScale returns the registered scale with the given name. An empty
+// name selects DefaultScaleName.
+func LookupScale(name string) (*Scale, error) {
+	if name == "" {
+		name = DefaultScaleName
+	}
+	s, ok := scales[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scale: %s (available: %v)", name, ScaleNames())
+	}
+	return s, nil
+}
+
+// RegisterScale adds or replaces a named scale. It is meant to be called
+// during initialization, before any chart is rendered.
+func RegisterScale(s *Scale) {
+	scales[s.Name] = s
+}
+
+func ScaleNames() []string {
+	names := make([]string, 0, len(scales))
+	for name := range scales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Index returns the position of level within the scale.
+func (s *Scale) Index(level string) (int, bool) {
+	idx, ok := s.index[level]
+	return idx, ok
+}
+
+func (s *Scale) WeightedAverage(votes map[string]int) float64 {
+	return s.TallyAverage(TallyCounts(votes))
+}
+
+// TallyAverage is the mean of the level midpoints, each counted as many
+// times as the tally's weight for its level.
+func (s *Scale) TallyAverage(votes Tally) float64 {
+	var totalWeight float64
+	var totalVotes float64
+
+	// Summing in scale order keeps the result independent of map order.
+	for _, level := range s.Levels {
+		count := votes[level]
+		totalWeight += s.Midpoints[level] * count
+		totalVotes += count
+	}
+
+	if totalVotes == 0 {
+		return 0
+	}
+	return totalWeight / totalVotes
+}
+
+// AverageToLabel returns the level whose range contains avg, or the hardest
+// level if none does.
+func (s *Scale) AverageToLabel(avg float64) string {
+	for _, level := range s.Levels {
+		r := s.Ranges[level]
+		if avg >= r.Lower {
+			if r.UpperInclusive {
+				if avg <= r.Upper {
+					return level
+				}
+			} else {
+				if avg < r.Upper {
+					return level
+				}
+			}
+		}
+	}
+	return s.Levels[len(s.Levels)-1]
+}
+
+// Window returns the inclusive range of level indices to draw: every voted
+// level and every level in markers, plus one neighbor on each side, widened
+// to at least minWindowSize levels when the scale has that many.
+func (s *Scale) Window(votes map[string]int, markers ...string) (minIdx, maxIdx int) {
+	return s.TallyWindow(TallyCounts(votes), markers...)
+}
+
+// TallyWindow is Window for a weighted tally.
+func (s *Scale) TallyWindow(votes Tally, markers ...string) (minIdx, maxIdx int) {
+	numLevels := len(s.Levels)
+	windowMin := minWindowSize
+	if windowMin > numLevels {
+		windowMin = numLevels
+	}
+
+	minVoted := numLevels
+	maxVoted := -1
+
+	for level, count := range votes {
+		if count > 0 {
+			if idx, ok := s.Index(level); ok {
+				if idx < minVoted {
+					minVoted = idx
+				}
+				if idx > maxVoted {
+					maxVoted = idx
+				}
+			}
+		}
+	}
+	for _, level := range markers {
+		if idx, ok := s.Index(level); ok {
+			minVoted = min(minVoted, idx)
+			maxVoted = max(maxVoted, idx)
+		}
+	}
+
+	if maxVoted == -1 {
+		return 0, windowMin - 1
+	}
+
+	minIdx = minVoted - 1
+	maxIdx = maxVoted + 1
+
+	if minIdx < 0 {
+		minIdx = 0
+	}
+	if maxIdx > numLevels-1 {
+		maxIdx = numLevels - 1
+	}
+
+	windowSize := maxIdx - minIdx + 1
+	if windowSize < windowMin {
+		deficit := windowMin - windowSize
+		expandLeft := deficit / 2
+		expandRight := deficit - expandLeft
+
+		minIdx -= expandLeft
+		maxIdx += expandRight
+
+		if minIdx < 0 {
+			maxIdx -= minIdx
+			minIdx = 0
+		}
+		if maxIdx > numLevels-1 {
+			minIdx -= maxIdx - (numLevels - 1)
+			maxIdx = numLevels - 1
+		}
+		if minIdx < 0 {
+			minIdx = 0
+		}
+	}
+
+	return minIdx, maxIdx
+}
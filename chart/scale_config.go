@@ -0,0 +1,123 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScaleConfig is the on-disk form of a set of scales, read from JSON or YAML:
+//
+//	scales:
+//	  - name: arena
+//	    levels:
+//	      - {name: Bronze, midpoint: 1, lower: 0, upper: 2, color: "#cd7f32"}
+//	      - {name: Silver, midpoint: 3, lower: 2, upper: 4, upper_inclusive: true, color: "#c0c0c0"}
+//
+// Levels are listed from easiest to hardest.
+type ScaleConfig struct {
+	Scales []ScaleDefinition `json:"scales" yaml:"scales"`
+}
+
+type ScaleDefinition struct {
+	Name   string            `json:"name" yaml:"name"`
+	Levels []LevelDefinition `json:"levels" yaml:"levels"`
+}
+
+type LevelDefinition struct {
+	Name           string  `json:"name" yaml:"name"`
+	Midpoint       float64 `json:"midpoint" yaml:"midpoint"`
+	Lower          float64 `json:"lower" yaml:"lower"`
+	Upper          float64 `json:"upper" yaml:"upper"`
+	UpperInclusive bool    `json:"upper_inclusive,omitempty" yaml:"upper_inclusive,omitempty"`
+	Color          string  `json:"color,omitempty" yaml:"color,omitempty"`
+}
+
+// ParseScaleConfig decodes a scale file. Files ending in .yaml or .yml are
+// read as YAML and everything else as JSON.
+func ParseScaleConfig(path string, data []byte) (*ScaleConfig, error) {
+	var cfg ScaleConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// Scale converts the definition into a Scale. It rejects definitions the
+// renderer cannot draw at all; range consistency is not checked here.
+func (d ScaleDefinition) Scale() (*Scale, error) {
+	if d.Name == "" {
+		return nil, errors.New("missing name")
+	}
+	if len(d.Levels) == 0 {
+		return nil, fmt.Errorf("scale %s: no levels", d.Name)
+	}
+
+	levels := make([]string, len(d.Levels))
+	midpoints := make(map[string]float64, len(d.Levels))
+	ranges := make(map[string]DifficultyRange, len(d.Levels))
+	colors := make(map[string]Color, len(d.Levels))
+	for i, lv := range d.Levels {
+		if lv.Name == "" {
+			return nil, fmt.Errorf("scale %s: levels[%d]: missing name", d.Name, i)
+		}
+		if _, dup := ranges[lv.Name]; dup {
+			return nil, fmt.Errorf("scale %s: duplicate level %q", d.Name, lv.Name)
+		}
+		levels[i] = lv.Name
+		midpoints[lv.Name] = lv.Midpoint
+		ranges[lv.Name] = DifficultyRange{Lower: lv.Lower, Upper: lv.Upper, UpperInclusive: lv.UpperInclusive}
+		if lv.Color != "" {
+			col, err := ParseHexColor(lv.Color)
+			if err != nil {
+				return nil, fmt.Errorf("scale %s: level %q: %w", d.Name, lv.Name, err)
+			}
+			colors[lv.Name] = col
+		}
+	}
+	return NewScale(d.Name, levels, midpoints, ranges, colors), nil
+}
+
+// LoadScales reads a scale file and returns its scales in file order.
+func LoadScales(path string) ([]*Scale, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ParseScaleConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*Scale, 0, len(cfg.Scales))
+	seen := make(map[string]bool, len(cfg.Scales))
+	for i, def := range cfg.Scales {
+		s, err := def.Scale()
+		if err != nil {
+			return nil, fmt.Errorf("%s: scales[%d]: %w", path, i, err)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("%s: duplicate scale %s", path, s.Name)
+		}
+		seen[s.Name] = true
+		out = append(out, s)
+	}
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package chart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const arenaYAML = `
+scales:
+  - name: arena
+    levels:
+      - {name: Bronze, midpoint: 1, lower: 0, upper: 2, color: "#cd7f32"}
+      - {name: Silver, midpoint: 3, lower: 2, upper: 4}
+      - {name: Gold, midpoint: 5, lower: 4, upper: 6, upper_inclusive: true, color: "#ffd700"}
+`
+
+const arenaJSON = `{"scales": [{"name": "arena", "levels": [
+	{"name": "Bronze", "midpoint": 1, "lower": 0, "upper": 2, "color": "#cd7f32"},
+	{"name": "Silver", "midpoint": 3, "lower": 2, "upper": 4},
+	{"name": "Gold", "midpoint": 5, "lower": 4, "upper": 6, "upper_inclusive": true, "color": "#ffd700"}
+]}]}`
+
+func writeScaleFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadScales(t *testing.T) {
+	for _, file := range []struct{ name, content string }{
+		{"scales.yaml", arenaYAML},
+		{"scales.json", arenaJSON},
+	} {
+		t.Run(file.name, func(t *testing.T) {
+			loaded, err := LoadScales(writeScaleFile(t, file.name, file.content))
+			if err != nil {
+				t.Fatalf("LoadScales error: %v", err)
+			}
+			if len(loaded) != 1 {
+				t.Fatalf("got %d scales, want 1", len(loaded))
+			}
+			s := loaded[0]
+			if s.Name != "arena" || strings.Join(s.Levels, ",") != "Bronze,Silver,Gold" {
+				t.Errorf("got %s %v", s.Name, s.Levels)
+			}
+			if r := s.Ranges["Gold"]; r != (DifficultyRange{4, 6, true}) {
+				t.Errorf("Gold range = %+v", r)
+			}
+			if s.Midpoints["Silver"] != 3 {
+				t.Errorf("Silver midpoint = %v", s.Midpoints["Silver"])
+			}
+			if _, ok := s.Colors["Silver"]; ok {
+				t.Error("Silver should have no color")
+			}
+			if idx, ok := s.Index("Gold"); !ok || idx != 2 {
+				t.Errorf("Index(Gold) = %d, %v", idx, ok)
+			}
+		})
+	}
+}
+
+func TestLoadScalesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		errContain string
+	}{
+		{"bad json", "s.json", `{"scales": [`, "s.json"},
+		{"unknown field", "s.json", `{"scales": [{"name": "x", "tiers": []}]}`, "tiers"},
+		{"missing name", "s.yaml", "scales:\n  - levels: [{name: A}]\n", "missing name"},
+		{"no levels", "s.yaml", "scales:\n  - name: x\n", "no levels"},
+		{"duplicate level", "s.yaml", "scales:\n  - name: x\n    levels: [{name: A}, {name: A}]\n", `duplicate level "A"`},
+		{"bad color", "s.yaml", "scales:\n  - name: x\n    levels: [{name: A, color: red}]\n", `level "A"`},
+		{"duplicate scale", "s.yaml", "scales:\n  - {name: x, levels: [{name: A}]}\n  - {name: x, levels: [{name: A}]}\n", "duplicate scale x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadScales(writeScaleFile(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errContain) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.errContain)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package chart
+
+import "testing"
+
+// arenaScale is a small three-tier scale used to exercise non-default scales.
+func arenaScale() *Scale {
+	return NewScale("arena",
+		[]string{"Bronze", "Silver", "Gold"},
+		map[string]float64{"Bronze": 1, "Silver": 3, "Gold": 5},
+		map[string]DifficultyRange{
+			"Bronze": {0, 2, false},
+			"Silver": {2, 4, false},
+			"Gold":   {4, 6, true},
+		},
+		map[string]Color{"Bronze": hexColor("#cd7f32"), "Silver": hexColor("#c0c0c0"), "Gold": hexColor("#ffd700")},
+	)
+}
+
+func TestLookupScale(t *testing.T) {
+	s, err := LookupScale("")
+	if err != nil || s != GenjiScale {
+		t.Errorf("LookupScale(\"\") = %v, %v; want genji", s, err)
+	}
+	if _, err := LookupScale("arena"); err == nil {
+		t.Error("expected error for unregistered scale")
+	}
+}
+
+func TestGenjiScaleMatchesPackageFuncs(t *testing.T) {
+	votes := map[string]int{"Medium": 5, "Hard -": 2}
+	if got, want := GenjiScale.WeightedAverage(votes), CalculateWeightedAverage(votes); got != want {
+		t.Errorf("WeightedAverage = %v, want %v", got, want)
+	}
+	minIdx, maxIdx := GenjiScale.Window(votes)
+	wantMin, wantMax := CalculateWindow(votes)
+	if minIdx != wantMin || maxIdx != wantMax {
+		t.Errorf("Window = (%d, %d), want (%d, %d)", minIdx, maxIdx, wantMin, wantMax)
+	}
+	if idx, ok := GenjiScale.Index("Hell"); !ok || idx != 15 {
+		t.Errorf("Index(Hell) = %d, %v", idx, ok)
+	}
+}
+
+func TestScaleSmallerThanMinWindow(t *testing.T) {
+	s := arenaScale()
+	tests := []struct {
+		name           string
+		votes          map[string]int
+		minIdx, maxIdx int
+	}{
+		{"no votes", map[string]int{}, 0, 2},
+		{"single vote", map[string]int{"Gold": 3}, 0, 2},
+		{"unknown level ignored", map[string]int{"Hell": 3}, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minIdx, maxIdx := s.Window(tt.votes)
+			if minIdx != tt.minIdx || maxIdx != tt.maxIdx {
+				t.Errorf("Window = (%d, %d), want (%d, %d)", minIdx, maxIdx, tt.minIdx, tt.maxIdx)
+			}
+		})
+	}
+}
+
+func TestScaleAverageToLabel(t *testing.T) {
+	s := arenaScale()
+	if avg := s.WeightedAverage(map[string]int{"Bronze": 1, "Gold": 1}); avg != 3 {
+		t.Errorf("WeightedAverage = %v, want 3", avg)
+	}
+	tests := []struct {
+		avg  float64
+		want string
+	}{
+		{0, "Bronze"},
+		{2, "Silver"},
+		{6, "Gold"},
+		{7, "Gold"},
+	}
+	for _, tt := range tests {
+		if got := s.AverageToLabel(tt.avg); got != tt.want {
+			t.Errorf("AverageToLabel(%v) = %q, want %q", tt.avg, got, tt.want)
+		}
+	}
+}
+
+func TestRenderChartCustomScale(t *testing.T) {
+	RegisterScale(arenaScale())
+	t.Cleanup(func() { delete(scales, "arena") })
+
+	data, err := RenderChart(map[string]int{"Silver": 4, "Gold": 1}, Options{Format: FormatPNG, DifficultyScale: "arena"})
+	if err != nil {
+		t.Fatalf("RenderChart error: %v", err)
+	}
+	if len(data) == 0 {
+		t.Error("empty image")
+	}
+
+	_, err = RenderChart(map[string]int{"Silver": 4}, Options{Format: FormatPNG, DifficultyScale: "arena", Colors: map[string]string{"Hard": "#fff"}})
+	if err == nil {
+		t.Error("expected error for a color override on a level outside the scale")
+	}
+}
@@ -0,0 +1,126 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// rangeTolerance absorbs float noise when comparing range boundaries written
+// as decimal literals.
+const rangeTolerance = 1e-9
+
+// Validate checks that the scale is internally consistent: every level has a
+// midpoint and range, no other levels are described, the ranges are ordered,
+// contiguous and non-overlapping, and each midpoint lies inside its own
+// range. Colors are optional; levels without one are drawn in
+// fallbackBarColor. All problems are reported, joined into one error.
+func (s *Scale) Validate() error {
+	var errs []error
+	report := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("scale %s: "+format, append([]any{s.Name}, args...)...))
+	}
+
+	if len(s.Levels) == 0 {
+		report("no levels")
+		return errors.Join(errs...)
+	}
+
+	seen := make(map[string]bool, len(s.Levels))
+	for _, level := range s.Levels {
+		if seen[level] {
+			report("duplicate level %q", level)
+		}
+		seen[level] = true
+
+		if _, ok := s.Midpoints[level]; !ok {
+			report("level %q: missing midpoint", level)
+		}
+		if _, ok := s.Ranges[level]; !ok {
+			report("level %q: missing range", level)
+		}
+	}
+	for _, extra := range unknownLevels(seen, s.Midpoints) {
+		report("midpoint for unknown level %q", extra)
+	}
+	for _, extra := range unknownLevels(seen, s.Ranges) {
+		report("range for unknown level %q", extra)
+	}
+	for _, extra := range unknownLevels(seen, s.Colors) {
+		report("color for unknown level %q", extra)
+	}
+
+	var prev string
+	for _, level := range s.Levels {
+		r, ok := s.Ranges[level]
+		if !ok {
+			continue
+		}
+		if r.Upper <= r.Lower {
+			report("level %q: empty range [%g, %g)", level, r.Lower, r.Upper)
+		}
+		if mid, ok := s.Midpoints[level]; ok && !r.contains(mid) {
+			report("level %q: midpoint %g outside its range %s", level, mid, r)
+		}
+
+		if prev != "" {
+			p := s.Ranges[prev]
+			switch {
+			case r.Lower < p.Lower-rangeTolerance:
+				report("levels out of order: %q %s starts below %q %s", level, r, prev, p)
+			case r.Lower > p.Upper+rangeTolerance:
+				report("gap between %q and %q: (%g, %g) is not covered", prev, level, p.Upper, r.Lower)
+			case r.Lower < p.Upper-rangeTolerance:
+				report("%q %s overlaps %q %s", prev, p, level, r)
+			case p.UpperInclusive:
+				report("%q %s overlaps %q %s at %g", prev, p, level, r, p.Upper)
+			}
+			if mid, ok := s.Midpoints[level]; ok {
+				if pm, ok := s.Midpoints[prev]; ok && mid <= pm {
+					report("levels out of order: midpoint of %q (%g) is not above %q (%g)", level, mid, prev, pm)
+				}
+			}
+		}
+		prev = level
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r DifficultyRange) contains(v float64) bool {
+	if v < r.Lower {
+		return false
+	}
+	if r.UpperInclusive {
+		return v <= r.Upper
+	}
+	return v < r.Upper
+}
+
+func (r DifficultyRange) String() string {
+	if r.UpperInclusive {
+		return fmt.Sprintf("[%g, %g]", r.Lower, r.Upper)
+	}
+	return fmt.Sprintf("[%g, %g)", r.Lower, r.Upper)
+}
+
+// unknownLevels returns the keys of m that are not in levels, sorted.
+func unknownLevels[V any](levels map[string]bool, m map[string]V) []string {
+	var out []string
+	for level := range m {
+		if !levels[level] {
+			out = append(out, level)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ValidateScales validates every registered scale.
+func ValidateScales() error {
+	var errs []error
+	for _, name := range ScaleNames() {
+		errs = append(errs, scales[name].Validate())
+	}
+	return errors.Join(errs...)
+}
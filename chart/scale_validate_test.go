@@ -0,0 +1,96 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenjiScaleValid(t *testing.T) {
+	if err := GenjiScale.Validate(); err != nil {
+		t.Errorf("built-in scale invalid:\n%v", err)
+	}
+	if err := arenaScale().Validate(); err != nil {
+		t.Errorf("arena scale invalid:\n%v", err)
+	}
+
+	// Levels without a color are drawn gray rather than rejected.
+	s := arenaScale()
+	delete(s.Colors, "Silver")
+	if err := s.Validate(); err != nil {
+		t.Errorf("arena scale without a Silver color invalid:\n%v", err)
+	}
+}
+
+func TestScaleValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *Scale)
+		want   []string
+	}{
+		{
+			name:   "gap",
+			modify: func(s *Scale) { s.Ranges["Silver"] = DifficultyRange{2.5, 4, false} },
+			want:   []string{`gap between "Bronze" and "Silver"`},
+		},
+		{
+			name:   "overlap",
+			modify: func(s *Scale) { s.Ranges["Silver"] = DifficultyRange{1.5, 4, false} },
+			want:   []string{`"Bronze" [0, 2) overlaps "Silver" [1.5, 4)`},
+		},
+		{
+			name:   "inclusive boundary overlaps",
+			modify: func(s *Scale) { s.Ranges["Bronze"] = DifficultyRange{0, 2, true} },
+			want:   []string{`overlaps "Silver" [2, 4) at 2`},
+		},
+		{
+			name:   "midpoint out of range",
+			modify: func(s *Scale) { s.Midpoints["Gold"] = 7 },
+			want:   []string{`level "Gold": midpoint 7 outside its range [4, 6]`},
+		},
+		{
+			name:   "missing midpoint and extra range",
+			modify: func(s *Scale) { delete(s.Midpoints, "Bronze"); s.Ranges["Platinum"] = DifficultyRange{6, 8, false} },
+			want:   []string{`level "Bronze": missing midpoint`, `range for unknown level "Platinum"`},
+		},
+		{
+			name:   "unordered levels",
+			modify: func(s *Scale) { s.Levels = []string{"Silver", "Bronze", "Gold"} },
+			want:   []string{`levels out of order: "Bronze" [0, 2) starts below "Silver"`, `midpoint of "Bronze" (1) is not above "Silver" (3)`},
+		},
+		{
+			name:   "empty range",
+			modify: func(s *Scale) { s.Ranges["Gold"] = DifficultyRange{4, 4, true} },
+			want:   []string{`level "Gold": empty range`},
+		},
+		{
+			name:   "duplicate level",
+			modify: func(s *Scale) { s.Levels = append(s.Levels, "Gold") },
+			want:   []string{`duplicate level "Gold"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := arenaScale()
+			tt.modify(s)
+			err := s.Validate()
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error:\n%v\nshould contain %q", err, want)
+				}
+			}
+			if !strings.HasPrefix(err.Error(), "scale arena: ") {
+				t.Errorf("error %q should name the scale", err)
+			}
+		})
+	}
+}
+
+func TestScaleValidateNoLevels(t *testing.T) {
+	err := NewScale("empty", nil, nil, nil, nil).Validate()
+	if err == nil || !strings.Contains(err.Error(), "no levels") {
+		t.Errorf("error = %v, want no levels", err)
+	}
+}
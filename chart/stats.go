@@ -0,0 +1,227 @@
+package chart
+
+import "math"
+
+// Stats summarizes a vote distribution. Every vote is treated as the
+// midpoint of its level, so the numeric fields share the scale used by the
+// weighted average and can be mapped back to a level with AverageToLabel.
+type Stats struct {
+	// TotalVotes is the summed weight of all votes; for plain counts it is
+	// the number of votes.
+	TotalVotes float64
+
+	Mean      float64
+	MeanLevel string
+
+	Median      float64
+	MedianLevel string
+
+	// Modes lists the most voted level(s) in scale order.
+	Modes []string
+
+	// Peaks lists the distinct local maxima of the distribution in scale
+	// order; more than one makes it Multimodal. AverageInValley is set when
+	// the mean lands on a thinly voted level between two peaks, so
+	// MeanLevel describes a difficulty few voters chose.
+	Peaks           []string
+	Multimodal      bool
+	AverageInValley bool
+
+	// StdDev is the population standard deviation.
+	StdDev float64
+
+	// Q1 and Q3 are the first and third quartiles; IQR is Q3 - Q1.
+	Q1  float64
+	Q3  float64
+	IQR float64
+
+	// CILow and CIHigh bound the ConfidenceLevel t interval for the mean,
+	// clamped to the scale; CILowLevel and CIHighLevel are their levels.
+	CILow       float64
+	CIHigh      float64
+	CILowLevel  string
+	CIHighLevel string
+
+	// Agreement is the Tastle-Wierman consensus of the votes: 1 when every
+	// vote is for the same level, 0 when they are split evenly between the
+	// two ends of the scale. Consensus classifies it.
+	Agreement float64
+	Consensus Consensus
+
+	// Shares has one entry per scale level, in scale order.
+	Shares []LevelShare
+}
+
+// Consensus is a coarse reading of Stats.Agreement.
+type Consensus string
+
+const (
+	ConsensusStrong Consensus = "strong consensus"
+	ConsensusMixed  Consensus = "mixed"
+	ConsensusSplit  Consensus = "split"
+)
+
+// Agreement thresholds for the Consensus classes.
+const (
+	StrongConsensusThreshold = 0.8
+	MixedConsensusThreshold  = 0.6
+)
+
+func classifyAgreement(agreement float64) Consensus {
+	switch {
+	case agreement >= StrongConsensusThreshold:
+		return ConsensusStrong
+	case agreement >= MixedConsensusThreshold:
+		return ConsensusMixed
+	default:
+		return ConsensusSplit
+	}
+}
+
+// LevelShare is the number of votes a level received and its percentage of
+// all votes.
+type LevelShare struct {
+	Level   string
+	Votes   float64
+	Percent float64
+}
+
+// CalculateStats summarizes votes on GenjiScale; see Scale.Stats.
+func CalculateStats(votes map[string]int) Stats {
+	return GenjiScale.Stats(votes)
+}
+
+// Stats computes the summary statistics of votes. Votes for levels outside
+// the scale are ignored.
+func (s *Scale) Stats(votes map[string]int) Stats {
+	return s.TallyStats(TallyCounts(votes))
+}
+
+// TallyStats is Stats for a weighted tally. Weights act as vote counts, so
+// a vote of weight 2 counts the same as two votes.
+func (s *Scale) TallyStats(votes Tally) Stats {
+	return s.SampleStats(votes, 0)
+}
+
+// SampleStats is TallyStats for weights that are not vote counts. n is the
+// effective number of votes, such as EffectiveVotes of the ballots behind
+// votes, and sets the width of the confidence interval; zero takes the
+// summed weight as TallyStats does.
+func (s *Scale) SampleStats(votes Tally, n float64) Stats {
+	st := Stats{Shares: make([]LevelShare, len(s.Levels))}
+
+	var maxCount float64
+	for i, level := range s.Levels {
+		count := votes[level]
+		st.Shares[i] = LevelShare{Level: level, Votes: count}
+		st.TotalVotes += count
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	if st.TotalVotes == 0 {
+		return st
+	}
+
+	var variance float64
+	st.Mean = s.TallyAverage(votes)
+	for i, level := range s.Levels {
+		count := st.Shares[i].Votes
+		st.Shares[i].Percent = count / st.TotalVotes * 100
+		if count == maxCount {
+			st.Modes = append(st.Modes, level)
+		}
+		d := s.Midpoints[level] - st.Mean
+		variance += d * d * count
+	}
+	st.StdDev = math.Sqrt(variance / st.TotalVotes)
+	st.Agreement = s.agreement(st.Shares, st.TotalVotes, st.Mean)
+	st.Consensus = classifyAgreement(st.Agreement)
+
+	st.Median = s.quantile(st.Shares, st.TotalVotes, 0.5)
+	st.Q1 = s.quantile(st.Shares, st.TotalVotes, 0.25)
+	st.Q3 = s.quantile(st.Shares, st.TotalVotes, 0.75)
+	st.IQR = st.Q3 - st.Q1
+
+	if n == 0 {
+		n = st.TotalVotes
+	}
+	st.CILow, st.CIHigh = s.confidenceInterval(st.Mean, st.StdDev, n)
+
+	st.MeanLevel = s.AverageToLabel(st.Mean)
+	st.CILowLevel = s.AverageToLabel(st.CILow)
+	st.CIHighLevel = s.AverageToLabel(st.CIHigh)
+	st.MedianLevel = s.AverageToLabel(st.Median)
+
+	counts := make([]float64, len(st.Shares))
+	for i, sh := range st.Shares {
+		counts[i] = sh.Votes
+	}
+	peaks := findPeaks(counts, st.TotalVotes)
+	for _, idx := range peaks {
+		st.Peaks = append(st.Peaks, s.Levels[idx])
+	}
+	st.Multimodal = len(peaks) > 1
+	if meanIdx, ok := s.Index(st.MeanLevel); ok {
+		st.AverageInValley = inValley(counts, peaks, meanIdx)
+	}
+	return st
+}
+
+// agreement computes the consensus measure of Tastle and Wierman (2007),
+//
+//	1 + sum(p_i * log2(1 - |x_i - mean| / width))
+//
+// where p_i is the share of votes for level i, x_i its midpoint and width
+// the distance between the first and last midpoints of the scale.
+func (s *Scale) agreement(shares []LevelShare, total, mean float64) float64 {
+	first, last := s.Midpoints[s.Levels[0]], s.Midpoints[s.Levels[len(s.Levels)-1]]
+	width := math.Abs(last - first)
+	if width == 0 {
+		return 1
+	}
+
+	cns := 1.0
+	for _, sh := range shares {
+		if sh.Votes == 0 {
+			continue
+		}
+		p := sh.Votes / total
+		// The mean lies strictly inside the scale unless all votes share a
+		// level, so d stays below 1 and the logarithm is finite.
+		d := math.Abs(s.Midpoints[sh.Level]-mean) / width
+		cns += p * math.Log2(1-d)
+	}
+	return math.Max(0, cns)
+}
+
+// quantile returns the q-th quantile of the votes, interpolating linearly
+// between the two closest ranks (the default method of R and NumPy). Weights
+// are treated as vote counts, so a total below one vote has a single rank.
+func (s *Scale) quantile(shares []LevelShare, total, q float64) float64 {
+	h := math.Max(0, (total-1)*q)
+	lo := math.Floor(h)
+	v := s.nthVote(shares, lo)
+	if frac := h - lo; frac > 0 {
+		v += frac * (s.nthVote(shares, lo+1) - v)
+	}
+	return v
+}
+
+// nthVote returns the midpoint of the n-th vote (0-based) when all votes are
+// sorted from easiest to hardest. Ranks past the last vote return the
+// hardest voted level.
+func (s *Scale) nthVote(shares []LevelShare, n float64) float64 {
+	last := shares[len(shares)-1].Level
+	for _, sh := range shares {
+		if sh.Votes == 0 {
+			continue
+		}
+		if n < sh.Votes {
+			return s.Midpoints[sh.Level]
+		}
+		n -= sh.Votes
+		last = sh.Level
+	}
+	return s.Midpoints[last]
+}
@@ -0,0 +1,152 @@
+package chart
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCalculateStats(t *testing.T) {
+	votes := map[string]int{
+		"Easy":     2,
+		"Easy +":   5,
+		"Medium -": 8,
+		"Medium":   12,
+		"Medium +": 6,
+		"Hard -":   3,
+		"Hard":     1,
+	}
+	st := CalculateStats(votes)
+
+	if st.TotalVotes != 37 {
+		t.Errorf("TotalVotes = %v, want 37", st.TotalVotes)
+	}
+	if !approx(st.Mean, CalculateWeightedAverage(votes)) || st.MeanLevel != "Medium" {
+		t.Errorf("Mean = %v (%s)", st.Mean, st.MeanLevel)
+	}
+	// The 19th of 37 sorted votes is a Medium vote.
+	if st.Median != 3.23 || st.MedianLevel != "Medium" {
+		t.Errorf("Median = %v (%s), want 3.23 (Medium)", st.Median, st.MedianLevel)
+	}
+	// Ranks 9 and 27 (0-based) fall on Medium - and Medium +.
+	if st.Q1 != 2.65 || st.Q3 != 3.83 || !approx(st.IQR, 3.83-2.65) {
+		t.Errorf("Q1, Q3, IQR = %v, %v, %v", st.Q1, st.Q3, st.IQR)
+	}
+	if !reflect.DeepEqual(st.Modes, []string{"Medium"}) {
+		t.Errorf("Modes = %v, want [Medium]", st.Modes)
+	}
+	if len(st.Shares) != len(DifficultyLevels) {
+		t.Fatalf("got %d shares, want %d", len(st.Shares), len(DifficultyLevels))
+	}
+	if sh := st.Shares[4]; sh.Level != "Medium" || sh.Votes != 12 || !approx(sh.Percent, 1200.0/37) {
+		t.Errorf("Medium share = %+v", sh)
+	}
+	if sh := st.Shares[15]; sh.Level != "Hell" || sh.Votes != 0 || sh.Percent != 0 {
+		t.Errorf("Hell share = %+v", sh)
+	}
+}
+
+func TestStatsSpread(t *testing.T) {
+	tests := []struct {
+		name   string
+		votes  map[string]int
+		median float64
+		stdDev float64
+		modes  []string
+	}{
+		{
+			name:   "unanimous",
+			votes:  map[string]int{"Hard": 4},
+			median: 5.0,
+			stdDev: 0,
+			modes:  []string{"Hard"},
+		},
+		{
+			name:   "polarized",
+			votes:  map[string]int{"Easy": 3, "Extreme": 3},
+			median: (1.47 + 8.53) / 2,
+			stdDev: (8.53 - 1.47) / 2,
+			modes:  []string{"Easy", "Extreme"},
+		},
+		{
+			name:   "interpolated median",
+			votes:  map[string]int{"Hard -": 1, "Hard": 1},
+			median: 4.71,
+			stdDev: 0.29,
+			modes:  []string{"Hard -", "Hard"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := CalculateStats(tt.votes)
+			if !approx(st.Median, tt.median) {
+				t.Errorf("Median = %v, want %v", st.Median, tt.median)
+			}
+			if !approx(st.StdDev, tt.stdDev) {
+				t.Errorf("StdDev = %v, want %v", st.StdDev, tt.stdDev)
+			}
+			if !reflect.DeepEqual(st.Modes, tt.modes) {
+				t.Errorf("Modes = %v, want %v", st.Modes, tt.modes)
+			}
+		})
+	}
+}
+
+func TestStatsNoVotes(t *testing.T) {
+	st := CalculateStats(map[string]int{})
+	if st.TotalVotes != 0 || st.Modes != nil || st.Median != 0 {
+		t.Errorf("stats for no votes = %+v", st)
+	}
+	if len(st.Shares) != len(DifficultyLevels) {
+		t.Errorf("got %d shares, want %d", len(st.Shares), len(DifficultyLevels))
+	}
+}
+
+func TestStatsAgreement(t *testing.T) {
+	tests := []struct {
+		name      string
+		votes     map[string]int
+		min, max  float64
+		consensus Consensus
+	}{
+		{"unanimous", map[string]int{"Hard": 9}, 1, 1, ConsensusStrong},
+		{"neighbors", map[string]int{"Hard -": 5, "Hard": 5}, 0.9, 1, ConsensusStrong},
+		{"two apart", map[string]int{"Easy": 5, "Hard": 5}, 0.6, 0.8, ConsensusMixed},
+		{"polarized", map[string]int{"Easy": 5, "Extreme": 5}, 0, 0.6, ConsensusSplit},
+		{"opposite ends", map[string]int{"Easy -": 5, "Hell": 5}, 0, 0, ConsensusSplit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := CalculateStats(tt.votes)
+			if st.Agreement < tt.min-1e-9 || st.Agreement > tt.max+1e-9 {
+				t.Errorf("Agreement = %v, want in [%v, %v]", st.Agreement, tt.min, tt.max)
+			}
+			if st.Consensus != tt.consensus {
+				t.Errorf("Consensus = %q, want %q", st.Consensus, tt.consensus)
+			}
+		})
+	}
+}
+
+func TestClassifyAgreement(t *testing.T) {
+	tests := []struct {
+		agreement float64
+		want      Consensus
+	}{
+		{1, ConsensusStrong},
+		{StrongConsensusThreshold, ConsensusStrong},
+		{0.79, ConsensusMixed},
+		{MixedConsensusThreshold, ConsensusMixed},
+		{0.59, ConsensusSplit},
+		{0, ConsensusSplit},
+	}
+	for _, tt := range tests {
+		if got := classifyAgreement(tt.agreement); got != tt.want {
+			t.Errorf("classifyAgreement(%v) = %q, want %q", tt.agreement, got, tt.want)
+		}
+	}
+}
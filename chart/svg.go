@@ -0,0 +1,49 @@
+//go:build !purego
+
+package chart
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ungerik/go-cairo"
+)
+
+func init() {
+	registerFormat(FormatSVG, "image/svg+xml")
+	vectorRenderers[FormatSVG] = renderSVG
+}
+
+// renderSVG draws the chart onto a cairo SVG surface. go-cairo can only
+// create vector surfaces backed by a file, so the document is written to a
+// temporary file and read back.
+func renderSVG(votes Tally, l layout, th *Theme, sc *Scale, opts Options) ([]byte, error) {
+	newSurface := func(path string) *cairo.Surface {
+		return cairo.NewSVGSurface(path, l.width, l.height, cairo.SVG_VERSION_1_2)
+	}
+	return renderVector("chart-*.svg", newSurface, func(surface *cairo.Surface) {
+		drawChart(newCairoCanvas(surface), l, th, sc, votes, opts)
+	})
+}
+
+// renderVector creates a file-backed surface in a temporary file, runs draw
+// against it and returns the finished document.
+func renderVector(pattern string, newSurface func(path string) *cairo.Surface, draw func(*cairo.Surface)) ([]byte, error) {
+	tmp, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	path := tmp.Name()
+	tmp.Close()
+	defer os.Remove(path)
+
+	surface := newSurface(path)
+	draw(surface)
+	surface.Finish()
+
+	if status := surface.GetStatus(); status != cairo.STATUS_SUCCESS {
+		return nil, fmt.Errorf("cairo surface error: status %d", status)
+	}
+
+	return os.ReadFile(path)
+}
@@ -0,0 +1,60 @@
+package chart
+
+import "fmt"
+
+// Tally is the weighted number of votes cast for each level. A plain vote
+// count is a tally in which every vote weighs 1.
+type Tally map[string]float64
+
+// TallyCounts converts plain vote counts into a Tally.
+func TallyCounts(votes map[string]int) Tally {
+	t := make(Tally, len(votes))
+	for level, count := range votes {
+		t[level] = float64(count)
+	}
+	return t
+}
+
+// EffectiveVotes returns Kish's effective sample size of ballots,
+// (Σw)²/Σw²: the number of equally weighted votes that carry as much
+// information. It is the number of ballots when all weights are equal and
+// does not change when every weight is scaled alike.
+func EffectiveVotes(ballots []Ballot) float64 {
+	var sum, sumSq float64
+	for _, b := range ballots {
+		sum += b.Weight
+		sumSq += b.Weight * b.Weight
+	}
+	if sumSq == 0 {
+		return 0
+	}
+	return sum * sum / sumSq
+}
+
+// Ballot is a single voter's vote for a level, counted Weight times.
+type Ballot struct {
+	Voter  string
+	Level  string
+	Weight float64
+}
+
+// MaxWeight is the largest weight a single vote may carry.
+const MaxWeight = 1000
+
+// ValidateWeight checks the weight of a single vote, which must be a number
+// from 0 to MaxWeight.
+func ValidateWeight(w float64) error {
+	if !(w >= 0 && w <= MaxWeight) {
+		return fmt.Errorf("weight must be a number from 0 to %d", MaxWeight)
+	}
+	return nil
+}
+
+// TallyBallots sums the weights of ballots per level.
+func TallyBallots(ballots []Ballot) Tally {
+	t := make(Tally)
+	for _, b := range ballots {
+		t[b.Level] += b.Weight
+	}
+	return t
+}
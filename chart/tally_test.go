@@ -0,0 +1,90 @@
+package chart
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTallyBallots(t *testing.T) {
+	got := TallyBallots([]Ballot{
+		{Voter: "a", Level: "Hard", Weight: 1},
+		{Voter: "b", Level: "Hard", Weight: 1.5},
+		{Voter: "c", Level: "Easy", Weight: 0.25},
+	})
+	want := Tally{"Hard": 2.5, "Easy": 0.25}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TallyBallots() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateWeight(t *testing.T) {
+	for _, w := range []float64{0, 0.25, 1, MaxWeight} {
+		if err := ValidateWeight(w); err != nil {
+			t.Errorf("ValidateWeight(%v) = %v", w, err)
+		}
+	}
+	for _, w := range []float64{-1, MaxWeight + 1, 1e308, math.Inf(1), math.NaN()} {
+		if err := ValidateWeight(w); err == nil {
+			t.Errorf("ValidateWeight(%v) succeeded, want an error", w)
+		}
+	}
+}
+
+func TestEffectiveVotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []float64
+		want    float64
+	}{
+		{"none", nil, 0},
+		{"equal weights", []float64{1, 1, 1}, 3},
+		{"scaled weights", []float64{0.4, 0.4, 0.4}, 3},
+		{"one heavy ballot", []float64{3}, 1},
+		{"uneven weights", []float64{3, 1}, 1.6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ballots := make([]Ballot, len(tt.weights))
+			for i, w := range tt.weights {
+				ballots[i] = Ballot{Level: "Hard", Weight: w}
+			}
+			if got := EffectiveVotes(ballots); !approx(got, tt.want) {
+				t.Errorf("EffectiveVotes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTallyStatsMatchesCounts(t *testing.T) {
+	votes := map[string]int{"Easy": 2, "Medium": 5, "Hard +": 1, "Hell": 1}
+	if got, want := GenjiScale.TallyStats(TallyCounts(votes)), GenjiScale.Stats(votes); !reflect.DeepEqual(got, want) {
+		t.Errorf("TallyStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTallyStatsWeighted(t *testing.T) {
+	// A veteran's vote at weight 2 counts like two votes.
+	weighted := GenjiScale.TallyStats(Tally{"Easy": 1, "Hard": 2})
+	counted := CalculateStats(map[string]int{"Easy": 1, "Hard": 2})
+	if !approx(weighted.Mean, counted.Mean) || !approx(weighted.Median, counted.Median) || weighted.MeanLevel != counted.MeanLevel {
+		t.Errorf("weighted mean %v median %v, want %v and %v", weighted.Mean, weighted.Median, counted.Mean, counted.Median)
+	}
+
+	st := GenjiScale.TallyStats(Tally{"Easy": 1.5, "Hard": 0.5})
+	if st.TotalVotes != 2 || !approx(st.Mean, (1.5*1.47+0.5*5)/2) {
+		t.Errorf("TotalVotes = %v, Mean = %v", st.TotalVotes, st.Mean)
+	}
+	if st.Median != 1.47 || len(st.Modes) != 1 || st.Modes[0] != "Easy" {
+		t.Errorf("Median = %v, Modes = %v, want 1.47 and [Easy]", st.Median, st.Modes)
+	}
+	if sh := st.Shares[7]; sh.Level != "Hard" || sh.Votes != 0.5 || sh.Percent != 25 {
+		t.Errorf("Shares[7] = %+v", sh)
+	}
+
+	// Less than one vote in total still has a median at its level.
+	st = GenjiScale.TallyStats(Tally{"Hard": 0.5})
+	if st.Median != 5 || st.Q1 != 5 || st.Q3 != 5 || st.CILow != 0 || st.CIHigh != 10 {
+		t.Errorf("half a vote: median %v, quartiles %v-%v, interval %v-%v", st.Median, st.Q1, st.Q3, st.CILow, st.CIHigh)
+	}
+}
@@ -0,0 +1,249 @@
+package chart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Color is an RGBA color with components in [0, 1].
+type Color struct {
+	R, G, B, A float64
+}
+
+func RGB(r, g, b float64) Color {
+	return Color{r, g, b, 1}
+}
+
+func RGBA(r, g, b, a float64) Color {
+	return Color{r, g, b, a}
+}
+
+// hexColor converts a built-in hex literal and panics if it is malformed.
+func hexColor(hex string) Color {
+	col, err := ParseHexColor(hex)
+	if err != nil {
+		panic(err)
+	}
+	return col
+}
+
+// luminance approximates perceived brightness, from 0 (black) to 1 (white).
+func (col Color) luminance() float64 {
+	return 0.2126*col.R + 0.7152*col.G + 0.0722*col.B
+}
+
+func setColor(c Canvas, col Color) {
+	c.SetSourceRGBA(col.R, col.G, col.B, col.A)
+}
+
+// Theme holds every color the renderer uses. Registered themes are shared
+// between requests and must not be modified.
+type Theme struct {
+	Name        string
+	Background  Color
+	Text        Color
+	TextShadow  Color
+	GridLine    Color
+	AverageLine Color
+	MedianLine  Color
+	// SubmittedMarker and OfficialMarker color the markers of a map's
+	// submitted and official difficulty.
+	SubmittedMarker Color
+	OfficialMarker  Color
+	IQRBand         Color
+	// ConfidenceBand is drawn over the bars, so it should stay faint.
+	ConfidenceBand Color
+	Warning        Color
+	BarShadow      Color
+	// GroupColors colors the stacked segments and average lines of voter
+	// groups, in group order.
+	GroupColors []Color
+	// ConsensusColors fills the agreement badge for each Consensus class.
+	ConsensusColors map[Consensus]Color
+	// DifficultyColors maps a difficulty level to its bar color. Levels
+	// without an entry are drawn in fallbackBarColor.
+	DifficultyColors map[string]Color
+}
+
+const DefaultThemeName = "dark"
+
+// groupColors is the Okabe-Ito qualitative palette without its yellow and
+// black, which vanish against the light and dark backgrounds.
+var groupColors = hexStops("#56b4e9", "#e69f00", "#009e73", "#cc79a7", "#0072b2", "#d55e00")
+
+var DarkTheme = &Theme{
+	Name:            "dark",
+	Background:      hexColor("#2b2d31"),
+	Text:            RGB(1, 1, 1),
+	TextShadow:      RGBA(0, 0, 0, 0.5),
+	GridLine:        RGBA(1, 1, 1, 0.15),
+	AverageLine:     RGB(1, 1, 1),
+	MedianLine:      hexColor("#5865f2"),
+	SubmittedMarker: hexColor("#eb459e"),
+	OfficialMarker:  hexColor("#00a8fc"),
+	IQRBand:         RGBA(1, 1, 1, 0.07),
+	ConfidenceBand:  RGBA(1, 1, 1, 0.12),
+	Warning:         hexColor("#f0b232"),
+	BarShadow:       RGBA(0, 0, 0, 0.3),
+	GroupColors:     groupColors,
+	ConsensusColors: map[Consensus]Color{
+		ConsensusStrong: hexColor("#23a55a"),
+		ConsensusMixed:  hexColor("#f0b232"),
+		ConsensusSplit:  hexColor("#f23f43"),
+	},
+	DifficultyColors: hexColors(DifficultyColors),
+}
+
+// LightTheme darkens the difficulty ramp so the pale greens and yellows
+// keep their contrast against a white background.
+var LightTheme = &Theme{
+	Name:            "light",
+	Background:      RGB(1, 1, 1),
+	Text:            hexColor("#313338"),
+	TextShadow:      RGBA(0, 0, 0, 0.08),
+	GridLine:        RGBA(0, 0, 0, 0.12),
+	AverageLine:     hexColor("#313338"),
+	MedianLine:      hexColor("#4752c4"),
+	SubmittedMarker: hexColor("#c1327c"),
+	OfficialMarker:  hexColor("#0070b8"),
+	IQRBand:         RGBA(0, 0, 0, 0.06),
+	ConfidenceBand:  RGBA(0.2, 0.2, 0.25, 0.1),
+	Warning:         hexColor("#f0b232"),
+	BarShadow:       RGBA(0, 0, 0, 0.12),
+	GroupColors:     groupColors,
+	ConsensusColors: map[Consensus]Color{
+		ConsensusStrong: hexColor("#1a7f45"),
+		ConsensusMixed:  hexColor("#c98a00"),
+		ConsensusSplit:  hexColor("#d22d39"),
+	},
+	DifficultyColors: hexColors(map[string]string{
+		"Easy -":      "#3cb043",
+		"Easy":        "#2e9e36",
+		"Easy +":      "#23862b",
+		"Medium -":    "#7cc21e",
+		"Medium":      "#6aab00",
+		"Medium +":    "#5a9400",
+		"Hard -":      "#e6b800",
+		"Hard":        "#e69f00",
+		"Hard +":      "#e68a00",
+		"Very Hard -": "#e67300",
+		"Very Hard":   "#cc6f00",
+		"Very Hard +": "#b35900",
+		"Extreme -":   "#e64500",
+		"Extreme":     "#c93a00",
+		"Extreme +":   "#a32800",
+		"Hell":        "#800000",
+	}),
+}
+
+var themes = map[string]*Theme{
+	DarkTheme.Name:  DarkTheme,
+	LightTheme.Name: LightTheme,
+}
+
+func hexColors(m map[string]string) map[string]Color {
+	out := make(map[string]Color, len(m))
+	for level, hex := range m {
+		out[level] = hexColor(hex)
+	}
+	return out
+}
+
+// LookupTheme returns the registered theme with the given name. An empty
+// name selects DefaultThemeName.
+func LookupTheme(name string) (*Theme, error) {
+	if name == "" {
+		name = DefaultThemeName
+	}
+	th, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme: %s (available: %v)", name, ThemeNames())
+	}
+	return th, nil
+}
+
+// RegisterTheme adds or replaces a named theme. It is meant to be called
+// during initialization, before any chart is rendered.
+func RegisterTheme(th *Theme) {
+	themes[th.Name] = th
+}
+
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fallbackBarColor is used for levels that neither the theme nor the scale
+// gives a color.
+var fallbackBarColor = hexColor("#808080")
+
+func (th *Theme) barColor(level string) Color {
+	if col, ok := th.DifficultyColors[level]; ok {
+		return col
+	}
+	return fallbackBarColor
+}
+
+// withScaleColors returns a copy of th whose bar colors are the defaults of
+// sc. Theme ramps are tuned for GenjiScale and do not carry over to other
+// scales.
+func (th *Theme) withScaleColors(sc *Scale) *Theme {
+	out := *th
+	out.DifficultyColors = sc.Colors
+	return &out
+}
+
+// Keys accepted in a color override map besides the difficulty level names.
+const (
+	ColorKeyBackground = "background"
+	ColorKeyText       = "text"
+)
+
+// ParseColorOverrides validates a map of color overrides keyed by a level of
+// sc, ColorKeyBackground or ColorKeyText. Keys are checked in sorted order so
+// the same bad request always reports the same key.
+func ParseColorOverrides(sc *Scale, overrides map[string]string) (map[string]Color, error) {
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]Color, len(overrides))
+	for _, key := range keys {
+		if _, ok := sc.Index(key); !ok && key != ColorKeyBackground && key != ColorKeyText {
+			return nil, fmt.Errorf("colors: unknown key %q (want a difficulty level, %q or %q)", key, ColorKeyBackground, ColorKeyText)
+		}
+		col, err := ParseHexColor(strings.TrimSpace(overrides[key]))
+		if err != nil {
+			return nil, fmt.Errorf("colors[%q]: %v", key, err)
+		}
+		out[key] = col
+	}
+	return out, nil
+}
+
+// withColors returns a copy of th with the parsed overrides applied.
+func (th *Theme) withColors(overrides map[string]Color) *Theme {
+	out := *th
+	out.DifficultyColors = make(map[string]Color, len(th.DifficultyColors))
+	for level, col := range th.DifficultyColors {
+		out.DifficultyColors[level] = col
+	}
+	for key, col := range overrides {
+		switch key {
+		case ColorKeyBackground:
+			out.Background = col
+		case ColorKeyText:
+			out.Text = col
+		default:
+			out.DifficultyColors[key] = col
+		}
+	}
+	return &out
+}
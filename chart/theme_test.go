@@ -0,0 +1,131 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestLookupTheme(t *testing.T) {
+	th, err := LookupTheme("")
+	if err != nil || th != DarkTheme {
+		t.Errorf("LookupTheme(\"\") = %v, %v; want dark theme", th, err)
+	}
+	th, err = LookupTheme("light")
+	if err != nil || th != LightTheme {
+		t.Errorf("LookupTheme(light) = %v, %v; want light theme", th, err)
+	}
+	if _, err := LookupTheme("neon"); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+}
+
+func TestThemesCoverEveryLevel(t *testing.T) {
+	for _, name := range ThemeNames() {
+		th, _ := LookupTheme(name)
+		for _, level := range DifficultyLevels {
+			if _, ok := th.DifficultyColors[level]; !ok {
+				t.Errorf("theme %s has no color for %q", name, level)
+			}
+		}
+	}
+}
+
+func TestThemesCoverEveryConsensus(t *testing.T) {
+	for _, name := range ThemeNames() {
+		th, _ := LookupTheme(name)
+		for _, c := range []Consensus{ConsensusStrong, ConsensusMixed, ConsensusSplit} {
+			if _, ok := th.ConsensusColors[c]; !ok {
+				t.Errorf("theme %s has no color for %q", name, c)
+			}
+		}
+	}
+}
+
+func TestDarkThemeMatchesDifficultyColors(t *testing.T) {
+	for level, hex := range DifficultyColors {
+		if got, want := DarkTheme.barColor(level), hexColor(hex); got != want {
+			t.Errorf("dark %q = %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestRenderChartTheme(t *testing.T) {
+	useBackend(t, "go")
+	votes := map[string]int{"Hard": 4}
+
+	data, err := RenderChart(votes, Options{Format: FormatPNG, Theme: "light"})
+	if err != nil {
+		t.Fatalf("RenderChart error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	r, g, b, _ := img.At(2, 2).RGBA()
+	if r>>8 != 255 || g>>8 != 255 || b>>8 != 255 {
+		t.Errorf("light background pixel = (%d, %d, %d), want white", r>>8, g>>8, b>>8)
+	}
+
+	if _, err := RenderChart(votes, Options{Format: FormatPNG, Theme: "neon"}); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+}
+
+func TestParseColorOverrides(t *testing.T) {
+	got, err := ParseColorOverrides(GenjiScale, map[string]string{"Hell": "#000", ColorKeyBackground: "#ffffff80"})
+	if err != nil {
+		t.Fatalf("ParseColorOverrides error: %v", err)
+	}
+	if got["Hell"] != RGB(0, 0, 0) || got[ColorKeyBackground] != RGBA(1, 1, 1, 128.0/255) {
+		t.Errorf("ParseColorOverrides = %v", got)
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		errKey    string
+	}{
+		{"bad hex", map[string]string{"Easy": "#12"}, `"Easy"`},
+		{"unknown key", map[string]string{"grid": "#fff"}, `"grid"`},
+		{"first bad key in order", map[string]string{"text": "x", "Hard": "y"}, `"Hard"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseColorOverrides(GenjiScale, tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.errKey) {
+				t.Errorf("error = %v, want mention of %s", err, tt.errKey)
+			}
+		})
+	}
+}
+
+func TestWithColorsCopiesTheme(t *testing.T) {
+	th := DarkTheme.withColors(map[string]Color{"Hard": RGB(0, 0, 1), ColorKeyText: RGB(1, 0, 0)})
+	if th.barColor("Hard") != RGB(0, 0, 1) || th.Text != RGB(1, 0, 0) {
+		t.Errorf("overrides not applied: %v, %v", th.barColor("Hard"), th.Text)
+	}
+	if DarkTheme.barColor("Hard") == RGB(0, 0, 1) || DarkTheme.Text != RGB(1, 1, 1) {
+		t.Error("withColors modified the shared theme")
+	}
+}
+
+func TestRenderChartColorOverrides(t *testing.T) {
+	useBackend(t, "go")
+	data, err := RenderChart(map[string]int{"Hard": 4}, Options{
+		Format: FormatPNG,
+		Colors: map[string]string{ColorKeyBackground: "#102030"},
+	})
+	if err != nil {
+		t.Fatalf("RenderChart error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	r, g, b, _ := img.At(2, 2).RGBA()
+	if r>>8 != 0x10 || g>>8 != 0x20 || b>>8 != 0x30 {
+		t.Errorf("background pixel = (%d, %d, %d), want (16, 32, 48)", r>>8, g>>8, b>>8)
+	}
+}
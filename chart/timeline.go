@@ -0,0 +1,357 @@
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimedVote is a vote for a level cast at a known time, counted Weight
+// times.
+type TimedVote struct {
+	Time   time.Time
+	Level  string
+	Weight float64
+}
+
+// TimelinePoint is the average of all votes cast up to and including the
+// vote for Level at Time.
+type TimelinePoint struct {
+	Time    time.Time
+	Level   string
+	Votes   float64
+	Average float64
+}
+
+const (
+	// DefaultStabilityVotes is the number of latest votes the stability
+	// indicator measures when Options.StabilityVotes is zero.
+	DefaultStabilityVotes = 5
+	// StableTierFraction is how far, as a fraction of the width of the
+	// current level, the latest votes may move the average for it to count
+	// as stable.
+	StableTierFraction = 0.25
+	// MaxTimelineSpan is the longest time a timeline's votes may span.
+	MaxTimelineSpan = 10 * 365 * 24 * time.Hour
+)
+
+// ValidateTimelineSpan checks that votes are cast within MaxTimelineSpan of
+// each other, so the time axis stays within what time.Duration can measure.
+func ValidateTimelineSpan(votes []TimedVote) error {
+	if len(votes) == 0 {
+		return nil
+	}
+	first, last := votes[0].Time, votes[0].Time
+	for _, v := range votes[1:] {
+		if v.Time.Before(first) {
+			first = v.Time
+		}
+		if v.Time.After(last) {
+			last = v.Time
+		}
+	}
+	if last.Sub(first) > MaxTimelineSpan {
+		return fmt.Errorf("votes span more than %d days", MaxTimelineSpan/(24*time.Hour))
+	}
+	return nil
+}
+
+// Timeline returns the running average after each vote, oldest first. Votes
+// are ordered by time, keeping the given order for equal times; votes that
+// do not change the tally, such as zero weights or unknown levels, get no
+// point of their own.
+func (s *Scale) Timeline(votes []TimedVote, a Aggregation) []TimelinePoint {
+	sorted := make([]TimedVote, len(votes))
+	copy(sorted, votes)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	tally := make(Tally)
+	var points []TimelinePoint
+	for _, v := range sorted {
+		if _, ok := s.Index(v.Level); !ok || v.Weight <= 0 {
+			continue
+		}
+		tally[v.Level] += v.Weight
+		st := s.TallyStats(tally)
+		points = append(points, TimelinePoint{Time: v.Time, Level: v.Level, Votes: st.TotalVotes, Average: s.aggregate(st, a)})
+	}
+	return points
+}
+
+// Stability describes how much the latest votes moved the running average.
+type Stability struct {
+	// Votes is the number of latest votes measured.
+	Votes int
+	// Shift is the distance between the highest and lowest running average
+	// from just before the first measured vote up to the last one.
+	Shift float64
+	// Stable is set when Shift stays under StableTierFraction of the width
+	// of the final average's level. Timelines with no more than n votes are
+	// never stable.
+	Stable bool
+}
+
+// Stability measures the last n points of a Timeline.
+func (s *Scale) Stability(points []TimelinePoint, n int) Stability {
+	if len(points) == 0 {
+		return Stability{}
+	}
+	enough := len(points) > n
+	first := max(len(points)-1-n, 0)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range points[first:] {
+		lo, hi = math.Min(lo, p.Average), math.Max(hi, p.Average)
+	}
+	st := Stability{Votes: len(points) - 1 - first, Shift: hi - lo}
+	if enough {
+		r := s.Ranges[s.AverageToLabel(points[len(points)-1].Average)]
+		st.Stable = st.Shift < StableTierFraction*(r.Upper-r.Lower)
+	}
+	return st
+}
+
+// RenderTimeline renders the running average of timed votes as a step line
+// over time, on bands colored like the bars of the levels they cover. The
+// votes measured by the stability indicator in the header are shaded. Bar
+// options such as Patterns and the median, IQR, confidence and agreement
+// overlays do not apply.
+func RenderTimeline(votes []TimedVote, opts Options) ([]byte, error) {
+	tally := make(Tally)
+	for _, v := range votes {
+		if v.Weight > 0 {
+			tally[v.Level] += v.Weight
+		}
+	}
+	if !hasVotes(tally) {
+		return nil, errors.New("no votes provided")
+	}
+	if opts.StabilityVotes < 0 {
+		return nil, errors.New("stability votes must not be negative")
+	}
+	if err := ValidateTimelineSpan(votes); err != nil {
+		return nil, err
+	}
+	opts.timeline = votes
+	return RenderTally(tally, opts)
+}
+
+const (
+	timelineBandAlpha = 0.22
+	timelineDot       = 3.5
+	// timelineTickSpacing is the smallest distance between time labels.
+	timelineTickSpacing = 160
+)
+
+// timelineSteps are the tick intervals the time axis picks from.
+var timelineSteps = []time.Duration{
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour, 28 * 24 * time.Hour,
+}
+
+// timeTicks returns tick times on a round interval between start and end,
+// no more than maxTicks of them, and the interval used.
+func timeTicks(start, end time.Time, maxTicks int) ([]time.Time, time.Duration) {
+	span := end.Sub(start)
+	maxTicks = max(maxTicks, 1)
+	var step time.Duration
+	for _, s := range timelineSteps {
+		if int(span/s) < maxTicks {
+			step = s
+			break
+		}
+	}
+	if step == 0 {
+		// Longer spans take a multiple of the largest step, so the number
+		// of ticks stays bounded.
+		largest := timelineSteps[len(timelineSteps)-1]
+		step = largest * (span/largest/time.Duration(maxTicks) + 1)
+	}
+	var ticks []time.Time
+	for t := start.Truncate(step); !t.After(end); t = t.Add(step) {
+		if !t.Before(start) {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, step
+}
+
+func timeLabel(t time.Time, step time.Duration) string {
+	layout := "Jan 2"
+	if step < 24*time.Hour {
+		layout = "Jan 2 15:04"
+	}
+	return strings.ToUpper(t.UTC().Format(layout))
+}
+
+// drawTimeline is drawChart for RenderTimeline.
+func drawTimeline(c Canvas, l layout, th *Theme, sc *Scale, votes []TimedVote, opts Options) {
+	setColor(c, th.Background)
+	c.Rectangle(0, 0, l.width, l.height)
+	c.Fill()
+
+	points := sc.Timeline(votes, opts.Aggregation)
+	if len(points) == 0 {
+		return
+	}
+
+	start, end := points[0].Time, points[len(points)-1].Time
+	if !end.After(start) {
+		start, end = start.Add(-30*time.Minute), end.Add(30*time.Minute)
+	}
+	timeX := func(t time.Time) float64 {
+		return l.left() + float64(t.Sub(start))/float64(end.Sub(start))*l.chartWidth()
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		lo, hi = math.Min(lo, p.Average), math.Max(hi, p.Average)
+	}
+	minIdx, _ := sc.Index(sc.AverageToLabel(lo))
+	maxIdx, _ := sc.Index(sc.AverageToLabel(hi))
+	minIdx, maxIdx = max(minIdx-1, 0), min(maxIdx+1, len(sc.Levels)-1)
+	minValue := sc.Ranges[sc.Levels[minIdx]].Lower
+	maxValue := sc.Ranges[sc.Levels[maxIdx]].Upper
+	valueY := func(v float64) float64 {
+		return l.bottom() - (v-minValue)/(maxValue-minValue)*l.chartHeight()
+	}
+
+	drawLevelBands(c, l, th, sc, minIdx, maxIdx, valueY)
+	drawTimeAxis(c, l, th, start, end, timeX)
+
+	n := opts.StabilityVotes
+	if n == 0 {
+		n = DefaultStabilityVotes
+	}
+	stability := sc.Stability(points, n)
+	if stability.Votes > 0 {
+		drawBand(c, l, th.IQRBand, timeX(points[len(points)-1-stability.Votes].Time), l.right())
+	}
+
+	last := points[len(points)-1]
+	setColor(c, th.AverageLine)
+	c.SetLineWidth(l.px(2.5))
+	y := valueY(points[0].Average)
+	c.MoveTo(timeX(points[0].Time), y)
+	for _, p := range points[1:] {
+		x := timeX(p.Time)
+		c.LineTo(x, y)
+		y = valueY(p.Average)
+		c.LineTo(x, y)
+	}
+	c.LineTo(l.right(), y)
+	c.Stroke()
+
+	// Each vote is a dot in its level's color where it moved the average.
+	for _, p := range points {
+		x, y := timeX(p.Time), valueY(p.Average)
+		setColor(c, th.Background)
+		c.Arc(x, y, l.px(timelineDot+1.5), 0, 2*math.Pi)
+		c.Fill()
+		setColor(c, th.barColor(p.Level))
+		c.Arc(x, y, l.px(timelineDot), 0, 2*math.Pi)
+		c.Fill()
+	}
+
+	header := []headerLabel{
+		{text: opts.Aggregation.Mode.Label() + ": " + formatFloat(last.Average) + " (" + strings.ToUpper(sc.AverageToLabel(last.Average)) + ")", anchorX: l.left()},
+		{text: "VOTES: " + formatCount(last.Votes), anchorX: l.left()},
+		stabilityBadge(th, stability, n, l.right()),
+	}
+	drawHeaderLabels(c, l, th, header, l.right())
+}
+
+// stabilityBadge reports whether the last n votes left the average settled.
+func stabilityBadge(th *Theme, st Stability, n int, anchorX float64) headerLabel {
+	label := headerLabel{anchorX: anchorX, badge: true, fill: th.Warning}
+	switch {
+	case st.Stable:
+		label.text = "STABLE: LAST " + formatInt(st.Votes) + " VOTES MOVED " + formatFloat(st.Shift)
+		label.fill = th.ConsensusColors[ConsensusStrong]
+	case st.Votes < n:
+		label.text = "! UNSETTLED: NEEDS " + formatInt(n+1) + " VOTES"
+	default:
+		label.text = "! UNSETTLED: LAST " + formatInt(st.Votes) + " VOTES MOVED " + formatFloat(st.Shift)
+	}
+	return label
+}
+
+// drawLevelBands shades the value range of every level from minIdx to
+// maxIdx in the level's bar color and names it in the band's top-left
+// corner.
+func drawLevelBands(c Canvas, l layout, th *Theme, sc *Scale, minIdx, maxIdx int, valueY func(float64) float64) {
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(11))
+	for i := minIdx; i <= maxIdx; i++ {
+		level := sc.Levels[i]
+		r := sc.Ranges[level]
+		top, bottom := valueY(r.Upper), valueY(r.Lower)
+
+		col := th.barColor(level)
+		col.A *= timelineBandAlpha
+		setColor(c, col)
+		c.Rectangle(l.left(), top, l.chartWidth(), bottom-top)
+		c.Fill()
+
+		setColor(c, th.GridLine)
+		c.SetLineWidth(l.px(1))
+		c.MoveTo(l.left(), top)
+		c.LineTo(l.right(), top)
+		c.Stroke()
+
+		if bottom-top >= l.px(20) {
+			drawTextWithShadow(c, l, th, strings.ToUpper(level), l.left()+l.px(8), top+l.px(15))
+		}
+	}
+	drawYAxisValues(c, l, th, sc, minIdx, maxIdx, valueY)
+}
+
+// drawYAxisValues labels the level boundaries on the value axis, skipping
+// boundaries that would crowd their neighbors.
+func drawYAxisValues(c Canvas, l layout, th *Theme, sc *Scale, minIdx, maxIdx int, valueY func(float64) float64) {
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(12))
+	lastY := math.Inf(1)
+	for i := minIdx; i <= maxIdx+1; i++ {
+		var v float64
+		if i <= maxIdx {
+			v = sc.Ranges[sc.Levels[i]].Lower
+		} else {
+			v = sc.Ranges[sc.Levels[maxIdx]].Upper
+		}
+		y := valueY(v)
+		if lastY-y < l.px(18) {
+			continue
+		}
+		lastY = y
+		label := formatFloat(v)
+		extents := c.TextExtents(label)
+		drawTextWithShadow(c, l, th, label, l.left()-extents.Width-l.px(10), y+extents.Height/2)
+	}
+}
+
+// drawTimeAxis draws a grid line and a date label at every tick between
+// start and end.
+func drawTimeAxis(c Canvas, l layout, th *Theme, start, end time.Time, timeX func(time.Time) float64) {
+	maxTicks := int(l.chartWidth() / l.px(timelineTickSpacing))
+	ticks, step := timeTicks(start, end, maxTicks)
+
+	c.SelectFont(FontBankSans, false)
+	c.SetFontSize(l.px(14))
+	for _, t := range ticks {
+		x := timeX(t)
+		setColor(c, th.GridLine)
+		c.SetLineWidth(l.px(1))
+		c.MoveTo(x, l.top())
+		c.LineTo(x, l.bottom())
+		c.Stroke()
+
+		text := timeLabel(t, step)
+		w := c.TextExtents(text).Width
+		textX := math.Min(math.Max(x-w/2, l.left()-l.px(20)), l.width-w-l.px(5))
+		drawTextWithShadow(c, l, th, text, textX, l.bottom()+l.px(30))
+	}
+}
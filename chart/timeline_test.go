@@ -0,0 +1,159 @@
+package chart
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var timelineStart = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func timedVotes(levels ...string) []TimedVote {
+	votes := make([]TimedVote, len(levels))
+	for i, level := range levels {
+		votes[i] = TimedVote{Time: timelineStart.Add(time.Duration(i) * time.Hour), Level: level, Weight: 1}
+	}
+	return votes
+}
+
+func TestTimeline(t *testing.T) {
+	votes := timedVotes("Hard", "Medium", "Hard")
+	// Out of order input is sorted by time.
+	votes[0], votes[2] = votes[2], votes[0]
+	votes = append(votes,
+		TimedVote{Time: timelineStart.Add(5 * time.Hour), Level: "Hell", Weight: 0},
+		TimedVote{Time: timelineStart.Add(6 * time.Hour), Level: "Nope", Weight: 1},
+	)
+
+	points := GenjiScale.Timeline(votes, Aggregation{})
+	want := []float64{5.0, (5.0 + 3.23) / 2, (5.0 + 3.23 + 5.0) / 3}
+	if len(points) != len(want) {
+		t.Fatalf("Timeline() returned %d points, want %d", len(points), len(want))
+	}
+	for i, p := range points {
+		if math.Abs(p.Average-want[i]) > 1e-9 {
+			t.Errorf("points[%d].Average = %v, want %v", i, p.Average, want[i])
+		}
+		if p.Votes != float64(i+1) {
+			t.Errorf("points[%d].Votes = %v, want %d", i, p.Votes, i+1)
+		}
+		if !p.Time.Equal(timelineStart.Add(time.Duration(i) * time.Hour)) {
+			t.Errorf("points[%d].Time = %v", i, p.Time)
+		}
+	}
+	if points[1].Level != "Medium" {
+		t.Errorf("points[1].Level = %q, want Medium", points[1].Level)
+	}
+}
+
+func TestStability(t *testing.T) {
+	point := func(avgs ...float64) []TimelinePoint {
+		points := make([]TimelinePoint, len(avgs))
+		for i, a := range avgs {
+			points[i] = TimelinePoint{Average: a}
+		}
+		return points
+	}
+	tests := []struct {
+		name   string
+		points []TimelinePoint
+		n      int
+		want   Stability
+	}{
+		{"empty", nil, 3, Stability{}},
+		{"too few votes", point(5.0, 5.0, 5.0), 3, Stability{Votes: 2, Shift: 0}},
+		{"stable", point(3.0, 5.0, 5.05, 4.95, 5.0), 3, Stability{Votes: 3, Shift: 0.1, Stable: true}},
+		{"unsettled", point(5.0, 5.0, 4.5, 5.0, 5.0), 3, Stability{Votes: 3, Shift: 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenjiScale.Stability(tt.points, tt.n)
+			if got.Votes != tt.want.Votes || math.Abs(got.Shift-tt.want.Shift) > 1e-9 || got.Stable != tt.want.Stable {
+				t.Errorf("Stability() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeTicks(t *testing.T) {
+	tests := []struct {
+		name     string
+		span     time.Duration
+		maxTicks int
+		wantStep time.Duration
+	}{
+		{"hours", 5 * time.Hour, 6, time.Hour},
+		{"days", 5 * 24 * time.Hour, 6, 24 * time.Hour},
+		{"weeks", 60 * 24 * time.Hour, 6, 14 * 24 * time.Hour},
+		{"years", MaxTimelineSpan, 6, 22 * 28 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := timelineStart.Add(tt.span)
+			ticks, step := timeTicks(timelineStart, end, tt.maxTicks)
+			if step != tt.wantStep {
+				t.Errorf("step = %v, want %v", step, tt.wantStep)
+			}
+			if len(ticks) == 0 || len(ticks) > tt.maxTicks {
+				t.Errorf("got %d ticks, want 1..%d", len(ticks), tt.maxTicks)
+			}
+			for _, tick := range ticks {
+				if tick.Before(timelineStart) || tick.After(end) {
+					t.Errorf("tick %v outside [%v, %v]", tick, timelineStart, end)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTimelineSpan(t *testing.T) {
+	votes := timedVotes("Hard", "Hard")
+	if err := ValidateTimelineSpan(votes); err != nil {
+		t.Errorf("ValidateTimelineSpan(hours) = %v", err)
+	}
+	votes[0].Time = timelineStart.Add(MaxTimelineSpan)
+	if err := ValidateTimelineSpan(votes); err != nil {
+		t.Errorf("ValidateTimelineSpan(max span) = %v", err)
+	}
+	votes[1].Time = time.Date(1, 1, 2, 0, 0, 0, 0, time.UTC)
+	votes[0].Time = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if err := ValidateTimelineSpan(votes); err == nil {
+		t.Error("ValidateTimelineSpan(year 1 to 9999) succeeded, want an error")
+	}
+	if _, err := RenderTimeline(votes, Options{Format: FormatPNG}); err == nil {
+		t.Error("RenderTimeline(year 1 to 9999) succeeded, want an error")
+	}
+}
+
+func TestTimeLabel(t *testing.T) {
+	tm := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	if got := timeLabel(tm, time.Hour); got != "MAR 1 09:30" {
+		t.Errorf("timeLabel(hour) = %q", got)
+	}
+	if got := timeLabel(tm, 24*time.Hour); got != "MAR 1" {
+		t.Errorf("timeLabel(day) = %q", got)
+	}
+}
+
+func TestRenderTimeline(t *testing.T) {
+	useBackend(t, "go")
+	votes := timedVotes("Hard", "Medium +", "Hard -", "Hard", "Hard +", "Hard", "Hard")
+	for _, opts := range []Options{
+		{Format: FormatPNG},
+		{Format: FormatPNG, Theme: "light", Width: 400, Height: 200, StabilityVotes: 2},
+		{Format: FormatPNG, Aggregation: Aggregation{Mode: AggregateMedian}},
+	} {
+		data, err := RenderTimeline(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderTimeline(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	// A single vote, or several at one instant, still gets a time axis.
+	if data, err := RenderTimeline(votes[:1], Options{Format: FormatPNG}); err != nil || len(data) == 0 {
+		t.Errorf("RenderTimeline(one vote) = %d bytes, %v", len(data), err)
+	}
+	if _, err := RenderTimeline(nil, Options{Format: FormatPNG}); err == nil {
+		t.Error("RenderTimeline(nil) succeeded, want an error")
+	}
+}
@@ -0,0 +1,86 @@
+package chart
+
+import (
+	"strings"
+	"time"
+)
+
+// MapInfo identifies the map a chart's votes were cast on. A chart with any
+// field set gets a title block above the header row, and the plot moves
+// down to make room for it.
+type MapInfo struct {
+	Code    string
+	Name    string
+	Creator string
+	// ThreadID is the Discord thread the playtest ran in.
+	ThreadID string
+	// Date is the day of the playtest.
+	Date time.Time
+}
+
+// titleLine is one line of the title block; advance is the distance from
+// the previous baseline, or from the top of the canvas for the first line,
+// in design pixels.
+type titleLine struct {
+	text    string
+	font    string
+	bold    bool
+	size    float64
+	advance float64
+}
+
+// titleLines returns the lines of the title block: the map's code and name,
+// its creator and the playtest details, each only when known.
+func (m MapInfo) titleLines() []titleLine {
+	var lines []titleLine
+	title := m.Code
+	if m.Name != "" {
+		if title != "" {
+			title += " - "
+		}
+		title += m.Name
+	}
+	if title != "" {
+		lines = append(lines, titleLine{text: title, font: FontBankSans, bold: true, size: 24, advance: 40})
+	}
+	if m.Creator != "" {
+		lines = append(lines, titleLine{text: "by " + m.Creator, font: FontInter, size: 14, advance: 22})
+	}
+
+	var details []string
+	if m.ThreadID != "" {
+		details = append(details, "Playtest thread "+m.ThreadID)
+	}
+	if !m.Date.IsZero() {
+		details = append(details, m.Date.Format("Jan 2, 2006"))
+	}
+	if len(details) > 0 {
+		lines = append(lines, titleLine{text: strings.Join(details, " · "), font: FontInter, size: 12, advance: 20})
+	}
+
+	if len(lines) > 0 {
+		lines[0].advance = lines[0].size + 16
+	}
+	return lines
+}
+
+// withTitle reserves room for the title block of m above the header row.
+func (l layout) withTitle(m MapInfo) layout {
+	l.title = 0
+	for _, line := range m.titleLines() {
+		l.title += l.px(line.advance)
+	}
+	return l
+}
+
+// drawTitle draws the title block of m from the left edge of the plot,
+// shortening lines that would run past its right edge.
+func drawTitle(c Canvas, l layout, th *Theme, m MapInfo) {
+	y := 0.0
+	for _, line := range m.titleLines() {
+		y += l.px(line.advance)
+		c.SelectFont(line.font, line.bold)
+		c.SetFontSize(l.px(line.size))
+		drawTextWithShadow(c, l, th, fitText(c, line.text, l.chartWidth()), l.left(), y)
+	}
+}
@@ -0,0 +1,76 @@
+package chart
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTitleLines(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		info MapInfo
+		want []string
+	}{
+		{"none", MapInfo{}, nil},
+		{"code", MapInfo{Code: "KX7PQ"}, []string{"KX7PQ"}},
+		{"name", MapInfo{Name: "Temple"}, []string{"Temple"}},
+		{"code and name", MapInfo{Code: "KX7PQ", Name: "Temple", Creator: "genji"}, []string{"KX7PQ - Temple", "by genji"}},
+		{"details only", MapInfo{ThreadID: "123", Date: date}, []string{"Playtest thread 123 · Mar 1, 2026"}},
+		{"date only", MapInfo{Code: "KX7PQ", Date: date}, []string{"KX7PQ", "Mar 1, 2026"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range tt.info.titleLines() {
+				got = append(got, line.text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("titleLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutWithTitle(t *testing.T) {
+	if l := defaultLayout.withTitle(MapInfo{}); l.top() != defaultLayout.top() {
+		t.Errorf("top() = %v without a title, want %v", l.top(), defaultLayout.top())
+	}
+
+	full := MapInfo{Code: "KX7PQ", Creator: "genji", ThreadID: "123"}
+	l := defaultLayout.withTitle(full)
+	if l.top() <= defaultLayout.top() || l.bottom() != defaultLayout.bottom() {
+		t.Errorf("plot spans %v..%v, want it to start below %v and end at %v", l.top(), l.bottom(), defaultLayout.top(), defaultLayout.bottom())
+	}
+	lines := full.titleLines()
+	var last float64
+	for _, line := range lines {
+		last += line.advance
+	}
+	if headerBaseline(l) <= last {
+		t.Errorf("header row at %v overlaps the title block ending at %v", headerBaseline(l), last)
+	}
+
+	small := newLayout(400, 200, 1).withTitle(full)
+	if small.chartHeight() <= 0 {
+		t.Errorf("chart height %v on a small canvas", small.chartHeight())
+	}
+}
+
+func TestRenderChartMapInfo(t *testing.T) {
+	useBackend(t, "go")
+	info := MapInfo{Code: "KX7PQ", Name: "Temple of Anubis", Creator: "genji", ThreadID: "1234567890", Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	votes := map[string]int{"Hard": 3, "Hard +": 1}
+	for _, opts := range []Options{
+		{Format: FormatPNG, Map: info},
+		{Format: FormatPNG, Map: info, Width: 200, Height: 100},
+		{Format: FormatPNG, Map: info, Orientation: OrientationHorizontal, ShowAgreement: true},
+		{Format: FormatPNG, Map: info, Type: ChartDonut, Width: 400, Height: 400},
+	} {
+		data, err := RenderChart(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderChart(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// YAxis selects what the value axis of a bar chart measures.
+type YAxis string
+
+const (
+	// YAxisCount measures bars in votes.
+	YAxisCount YAxis = "count"
+	// YAxisPercent measures bars as each level's share of the votes, so
+	// charts with few and many votes can be compared side by side.
+	YAxisPercent YAxis = "percent"
+)
+
+var yAxes = map[YAxis]bool{
+	YAxisCount:   true,
+	YAxisPercent: true,
+}
+
+// LookupYAxis returns the named y axis. An empty name selects YAxisCount.
+func LookupYAxis(name string) (YAxis, error) {
+	if name == "" {
+		return YAxisCount, nil
+	}
+	a := YAxis(name)
+	if !yAxes[a] {
+		return "", fmt.Errorf("unknown y axis: %s (available: %v)", name, YAxisNames())
+	}
+	return a, nil
+}
+
+func YAxisNames() []string {
+	names := make([]string, 0, len(yAxes))
+	for a := range yAxes {
+		names = append(names, string(a))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tickLabel formats a value on the axis.
+func (a YAxis) tickLabel(v int) string {
+	if a == YAxisPercent {
+		return formatInt(v) + "%"
+	}
+	return formatInt(v)
+}
+
+// BarLabel selects the text above each bar.
+type BarLabel string
+
+const (
+	// BarLabelCount labels bars with their vote count.
+	BarLabelCount BarLabel = "count"
+	// BarLabelPercent labels bars with their share of the votes.
+	BarLabelPercent BarLabel = "percent"
+	// BarLabelBoth labels bars with the count followed by the share in
+	// parentheses.
+	BarLabelBoth BarLabel = "both"
+)
+
+var barLabels = map[BarLabel]bool{
+	BarLabelCount:   true,
+	BarLabelPercent: true,
+	BarLabelBoth:    true,
+}
+
+// LookupBarLabel returns the named bar label. An empty name selects
+// BarLabelCount; the charts themselves match an empty Options.BarLabels to
+// the y axis instead, see newBarValues.
+func LookupBarLabel(name string) (BarLabel, error) {
+	if name == "" {
+		return BarLabelCount, nil
+	}
+	b := BarLabel(name)
+	if !barLabels[b] {
+		return "", fmt.Errorf("unknown bar label: %s (available: %v)", name, BarLabelNames())
+	}
+	return b, nil
+}
+
+func BarLabelNames() []string {
+	names := make([]string, 0, len(barLabels))
+	for b := range barLabels {
+		names = append(names, string(b))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// barValues pairs the votes of a chart with the lengths of its bars, in
+// units of the y axis.
+type barValues struct {
+	votes  Tally
+	values Tally
+	total  float64
+	labels BarLabel
+}
+
+// newBarValues measures votes on axis. An empty labels follows the axis:
+// counts on a count axis and shares on a percent axis.
+func newBarValues(votes Tally, axis YAxis, labels BarLabel) barValues {
+	b := barValues{votes: votes, values: votes, total: sumTally(votes), labels: labels}
+	if axis == YAxisPercent {
+		b.values = shareTally(votes, b.total)
+	}
+	if b.labels == "" {
+		b.labels = BarLabelCount
+		if axis == YAxisPercent {
+			b.labels = BarLabelPercent
+		}
+	}
+	return b
+}
+
+// shareTally returns each level's weight in votes as a percentage of total.
+func shareTally(votes Tally, total float64) Tally {
+	shares := make(Tally, len(votes))
+	if total <= 0 {
+		return shares
+	}
+	for level, count := range votes {
+		shares[level] = count / total * 100
+	}
+	return shares
+}
+
+// shareGroups is shareTally for the votes of every group, as shares of the
+// total of all groups.
+func shareGroups(groups []Group, total float64) []Group {
+	out := make([]Group, len(groups))
+	for i, g := range groups {
+		out[i] = Group{Name: g.Name, Votes: shareTally(g.Votes, total)}
+	}
+	return out
+}
+
+// label returns the text above the bar of level.
+func (b barValues) label(level string) string {
+	count := b.votes[level]
+	switch b.labels {
+	case BarLabelPercent:
+		return percentText(count / b.total)
+	case BarLabelBoth:
+		return formatCount(count) + " (" + percentText(count/b.total) + ")"
+	default:
+		return formatCount(count)
+	}
+}
+
+// percentAxisStep is what the top of a percent axis is rounded up to, so
+// its quarter ticks fall on multiples of 5%.
+const percentAxisStep = 20
+
+// axisMax returns the top of the y axis for values of levels minIdx to
+// maxIdx: calculateMaxVotes on a count axis, and the largest share rounded
+// up to percentAxisStep on a percent axis.
+func axisMax(sc *Scale, values Tally, minIdx, maxIdx int, axis YAxis) int {
+	if axis != YAxisPercent {
+		return calculateMaxVotes(sc, values, minIdx, maxIdx)
+	}
+	var top float64
+	for i := minIdx; i <= maxIdx; i++ {
+		top = math.Max(top, values[sc.Levels[i]])
+	}
+	// The tolerance keeps rounding error in a share such as 40% from
+	// pushing the axis up a step.
+	return max(int(math.Ceil(top/percentAxisStep-1e-9))*percentAxisStep, percentAxisStep)
+}
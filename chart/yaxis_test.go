@@ -0,0 +1,125 @@
+package chart
+
+import "testing"
+
+func TestLookupYAxis(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    YAxis
+		wantErr bool
+	}{
+		{"", YAxisCount, false},
+		{"count", YAxisCount, false},
+		{"percent", YAxisPercent, false},
+		{"log", "", true},
+	}
+	for _, tt := range tests {
+		got, err := LookupYAxis(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("LookupYAxis(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestLookupBarLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    BarLabel
+		wantErr bool
+	}{
+		{"", BarLabelCount, false},
+		{"count", BarLabelCount, false},
+		{"percent", BarLabelPercent, false},
+		{"both", BarLabelBoth, false},
+		{"votes", "", true},
+	}
+	for _, tt := range tests {
+		got, err := LookupBarLabel(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("LookupBarLabel(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestBarValues(t *testing.T) {
+	votes := Tally{"Hard": 3, "Hard +": 1}
+	tests := []struct {
+		name   string
+		axis   YAxis
+		labels BarLabel
+		value  float64
+		label  string
+	}{
+		{"count", YAxisCount, "", 3, "3"},
+		{"percent", YAxisPercent, "", 75, "75%"},
+		{"percent axis with counts", YAxisPercent, BarLabelCount, 75, "3"},
+		{"count axis with both", YAxisCount, BarLabelBoth, 3, "3 (75%)"},
+		{"percent axis with both", YAxisPercent, BarLabelBoth, 75, "3 (75%)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBarValues(votes, tt.axis, tt.labels)
+			if b.values["Hard"] != tt.value {
+				t.Errorf("value = %v, want %v", b.values["Hard"], tt.value)
+			}
+			if got := b.label("Hard"); got != tt.label {
+				t.Errorf("label() = %q, want %q", got, tt.label)
+			}
+		})
+	}
+}
+
+func TestAxisMax(t *testing.T) {
+	sc := GenjiScale
+	tests := []struct {
+		name   string
+		values Tally
+		axis   YAxis
+		want   int
+	}{
+		{"count", Tally{"Hard": 7}, YAxisCount, 7},
+		{"percent rounds up", Tally{"Hard": 32.4}, YAxisPercent, 40},
+		{"percent on a step", Tally{"Hard": 40.000000000001}, YAxisPercent, 40},
+		{"percent all one level", Tally{"Hard": 100}, YAxisPercent, 100},
+		{"percent empty", Tally{}, YAxisPercent, percentAxisStep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := axisMax(sc, tt.values, 0, len(sc.Levels)-1, tt.axis); got != tt.want {
+				t.Errorf("axisMax() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderChartPercentAxis(t *testing.T) {
+	useBackend(t, "go")
+	votes := map[string]int{"Hard": 3, "Hard +": 1}
+	for _, opts := range []Options{
+		{Format: FormatPNG, YAxis: YAxisPercent},
+		{Format: FormatPNG, BarLabels: BarLabelBoth},
+		{Format: FormatPNG, YAxis: YAxisPercent, BarLabels: BarLabelCount, Orientation: OrientationHorizontal},
+	} {
+		data, err := RenderChart(votes, opts)
+		if err != nil || len(data) == 0 {
+			t.Errorf("RenderChart(%+v) = %d bytes, %v", opts, len(data), err)
+		}
+	}
+
+	groups := []Group{{Name: "a", Votes: Tally{"Hard": 2}}, {Name: "b", Votes: Tally{"Hard +": 1}}}
+	if _, err := RenderGroups(groups, Options{Format: FormatPNG, YAxis: YAxisPercent}); err != nil {
+		t.Errorf("RenderGroups() on a percent axis: %v", err)
+	}
+	if _, err := RenderComparison(Tally{"Medium": 2}, TallyCounts(votes), Options{Format: FormatPNG, YAxis: YAxisPercent, BarLabels: BarLabelBoth}); err != nil {
+		t.Errorf("RenderComparison() on a percent axis: %v", err)
+	}
+
+	for _, opts := range []Options{
+		{Format: FormatPNG, YAxis: "log"},
+		{Format: FormatPNG, BarLabels: "votes"},
+	} {
+		if _, err := RenderChart(votes, opts); err == nil {
+			t.Errorf("RenderChart(%+v) succeeded", opts)
+		}
+	}
+}
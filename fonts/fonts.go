@@ -0,0 +1,11 @@
+// Package fonts embeds the typefaces bundled with the service so that
+// renderers which cannot rely on fontconfig can load them directly.
+package fonts
+
+import _ "embed"
+
+//go:embed banksansefcy_med.otf
+var BankSans []byte
+
+//go:embed Inter-Regular.ttf
+var Inter []byte
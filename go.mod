@@ -1,8 +1,12 @@
 module github.com/genjishimada/playtest-plotter
 
-go 1.23
+go 1.23.0
 
 require (
-	github.com/kolesa-team/go-webp v1.0.5 // indirect
-	github.com/ungerik/go-cairo v0.0.0-20240304075741-47de8851d267 // indirect
+	github.com/kolesa-team/go-webp v1.0.5
+	github.com/ungerik/go-cairo v0.0.0-20240304075741-47de8851d267
+	golang.org/x/image v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/text v0.28.0 // indirect
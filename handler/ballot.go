@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+// Ballot is one voter's vote in the weighted request shape, used in place of
+// a votes map when some voters carry more weight than others.
+type Ballot struct {
+	Voter      string `json:"voter"`
+	Difficulty string `json:"difficulty"`
+	// Weight defaults to 1 when omitted or zero.
+	Weight float64 `json:"weight,omitempty"`
+	// Group optionally assigns the voter to a group; either every ballot
+	// names a group or none does.
+	Group string `json:"group,omitempty"`
+}
+
+func validateBallots(sc *chart.Scale, ballots []Ballot) error {
+	if len(ballots) == 0 {
+		return errors.New("no votes provided")
+	}
+
+	seen := make(map[string]bool, len(ballots))
+	grouped := ballots[0].Group != ""
+	for i, b := range ballots {
+		if b.Voter == "" {
+			return fmt.Errorf("ballots[%d]: missing voter", i)
+		}
+		if seen[b.Voter] {
+			return fmt.Errorf("duplicate voter: %s", b.Voter)
+		}
+		seen[b.Voter] = true
+
+		if _, ok := sc.Index(b.Difficulty); !ok {
+			return fmt.Errorf("invalid difficulty: %s", b.Difficulty)
+		}
+		if err := chart.ValidateWeight(b.Weight); err != nil {
+			return fmt.Errorf("invalid weight for voter %s: %w", b.Voter, err)
+		}
+		if (b.Group != "") != grouped {
+			return fmt.Errorf("ballots[%d]: group must be set on all ballots or none", i)
+		}
+	}
+
+	return nil
+}
+
+// chartBallots converts ballots, applying the default weight.
+func chartBallots(ballots []Ballot) []chart.Ballot {
+	cb := make([]chart.Ballot, len(ballots))
+	for i, b := range ballots {
+		weight := b.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		cb[i] = chart.Ballot{Voter: b.Voter, Level: b.Difficulty, Weight: weight}
+	}
+	return cb
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+func TestValidateBallots(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    bool
+		errContain string
+	}{
+		{
+			name: "weighted ballots",
+			body: `{"ballots":[{"voter":"1","difficulty":"Hard","weight":1.5},{"voter":"2","difficulty":"Hard +"}]}`,
+		},
+		{
+			name:       "empty ballots",
+			body:       `{"ballots":[]}`,
+			wantErr:    true,
+			errContain: "no votes",
+		},
+		{
+			name:       "votes and ballots",
+			body:       `{"votes":{"Hard":1},"ballots":[{"voter":"1","difficulty":"Hard"}]}`,
+			wantErr:    true,
+			errContain: "only one of votes, ballots and groups",
+		},
+		{
+			name: "grouped ballots",
+			body: `{"ballots":[{"voter":"1","difficulty":"Hard","weight":2,"group":"verified"},{"voter":"2","difficulty":"Easy","group":"community"}]}`,
+		},
+		{
+			name:       "partly grouped ballots",
+			body:       `{"ballots":[{"voter":"1","difficulty":"Hard","group":"verified"},{"voter":"2","difficulty":"Easy"}]}`,
+			wantErr:    true,
+			errContain: "ballots[1]: group must be set on all ballots or none",
+		},
+		{
+			name:       "missing voter",
+			body:       `{"ballots":[{"difficulty":"Hard"}]}`,
+			wantErr:    true,
+			errContain: "ballots[0]: missing voter",
+		},
+		{
+			name:       "duplicate voter",
+			body:       `{"ballots":[{"voter":"1","difficulty":"Hard"},{"voter":"1","difficulty":"Easy"}]}`,
+			wantErr:    true,
+			errContain: "duplicate voter: 1",
+		},
+		{
+			name:       "invalid difficulty",
+			body:       `{"ballots":[{"voter":"1","difficulty":"Harder"}]}`,
+			wantErr:    true,
+			errContain: "invalid difficulty: Harder",
+		},
+		{
+			name:       "negative weight",
+			body:       `{"ballots":[{"voter":"1","difficulty":"Hard","weight":-2}]}`,
+			wantErr:    true,
+			errContain: "invalid weight for voter 1",
+		},
+		{
+			name:       "huge weight",
+			body:       `{"ballots":[{"voter":"1","difficulty":"Hard","weight":1e308},{"voter":"2","difficulty":"Hard","weight":1e308}]}`,
+			wantErr:    true,
+			errContain: "invalid weight for voter 1: weight must be a number from 0 to 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(tt.body))
+
+			_, err := ParseAndValidate(req)
+			if tt.wantErr {
+				if err == nil || !contains(err.Error(), tt.errContain) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.errContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStatsHandlerBallots(t *testing.T) {
+	body := `{"ballots":[{"voter":"vet","difficulty":"Hard","weight":2.5},{"voter":"new","difficulty":"Easy"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/stats", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	StatsHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp StatsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.TotalVotes != 3.5 {
+		t.Errorf("total_votes = %v, want 3.5", resp.TotalVotes)
+	}
+	if want := (2.5*5.0 + 1.47) / 3.5; resp.Mean < want-1e-9 || resp.Mean > want+1e-9 {
+		t.Errorf("mean = %v, want %v", resp.Mean, want)
+	}
+	if resp.Shares[7].Votes != 2.5 {
+		t.Errorf("shares[7] = %+v, want 2.5 votes", resp.Shares[7])
+	}
+}
+
+func TestChartHandlerBallots(t *testing.T) {
+	body := `{"ballots":[{"voter":"vet","difficulty":"Hard","weight":2.5},{"voter":"new","difficulty":"Easy"}],"format":"png"}`
+	req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	ChartHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("wrong content type: got %s want image/png", ct)
+	}
+}
+
+func TestStatsHandlerBallotsConfidence(t *testing.T) {
+	body := `{"ballots":[{"voter":"vet","difficulty":"Hard","weight":3}]}`
+	req := httptest.NewRequest(http.MethodPost, "/stats", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	StatsHandler(rr, req)
+
+	var resp StatsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	sc := chart.GenjiScale
+	lo, hi := sc.Ranges[sc.Levels[0]].Lower, sc.Ranges[sc.Levels[len(sc.Levels)-1]].Upper
+	if resp.CILow != lo || resp.CIHigh != hi {
+		t.Errorf("interval = [%v, %v] for one voter, want the whole scale [%v, %v]", resp.CILow, resp.CIHigh, lo, hi)
+	}
+}
+
+func TestHandlersRejectHugeWeights(t *testing.T) {
+	body := `{"ballots":[{"voter":"1","difficulty":"Hard","weight":1e308},{"voter":"2","difficulty":"Hard","weight":1e308}],"format":"png"}`
+	for _, tt := range []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/stats", StatsHandler},
+		{"/chart", ChartHandler},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		tt.handler(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s returned status %d, want %d: %s", tt.path, rr.Code, http.StatusBadRequest, rr.Body.String())
+		}
+	}
+}
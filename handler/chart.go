@@ -5,41 +5,196 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/genjishimada/playtest-plotter/chart"
 )
 
 type ChartRequest struct {
 	Votes map[string]int `json:"votes"`
+	// Ballots and Groups replace Votes with individually weighted votes
+	// or votes split by voter group.
+	Ballots []Ballot    `json:"ballots,omitempty"`
+	Groups  []VoteGroup `json:"groups,omitempty"`
+	// PreviousVotes are the votes of an earlier round; when set, the chart
+	// compares them with Votes.
+	PreviousVotes map[string]int `json:"previous_votes,omitempty"`
+	Format        string         `json:"format,omitempty"`
+	Width         int            `json:"width,omitempty"`
+	Height        int            `json:"height,omitempty"`
+	Scale         float64        `json:"scale,omitempty"`
+	Theme         string         `json:"theme,omitempty"`
+	Palette       string         `json:"palette,omitempty"`
+	Patterns      bool           `json:"patterns,omitempty"`
+	// Colors overrides bar colors per difficulty level, plus the
+	// "background" and "text" colors, as hex strings.
+	Colors map[string]string `json:"colors,omitempty"`
+	// DifficultyScale names the scale the votes are cast on; empty selects
+	// the default scale.
+	DifficultyScale string `json:"difficulty_scale,omitempty"`
+	ShowMedian      bool   `json:"show_median,omitempty"`
+	ShowIQR         bool   `json:"show_iqr,omitempty"`
+	ShowAgreement   bool   `json:"show_agreement,omitempty"`
+	ShowConfidence  bool   `json:"show_confidence,omitempty"`
+	// Type is "bar" (the default) or "donut".
+	Type string `json:"type,omitempty"`
+	// Orientation is "vertical" (the default) or "horizontal".
+	Orientation string `json:"orientation,omitempty"`
+	// YAxis is "count" (the default) or "percent". BarLabels is "count",
+	// "percent" or "both"; by default it follows YAxis.
+	YAxis     string `json:"y_axis,omitempty"`
+	BarLabels string `json:"bar_labels,omitempty"`
+	// Aggregation selects how votes are combined into the average; see
+	// chart.LookupAggregation. SubmittedDifficulty and PriorWeight
+	// configure the "bayesian" mode.
+	Aggregation         string  `json:"aggregation,omitempty"`
+	SubmittedDifficulty string  `json:"submitted_difficulty,omitempty"`
+	PriorWeight         float64 `json:"prior_weight,omitempty"`
+	// OfficialDifficulty is the level the map was given. It is marked on
+	// the chart along with SubmittedDifficulty.
+	OfficialDifficulty string `json:"official_difficulty,omitempty"`
+	// MapCode, MapName, Creator, ThreadID and Date describe the map in a
+	// title block above the chart. Date is a YYYY-MM-DD day.
+	MapCode  string `json:"map_code,omitempty"`
+	MapName  string `json:"map_name,omitempty"`
+	Creator  string `json:"creator,omitempty"`
+	ThreadID string `json:"thread_id,omitempty"`
+	Date     string `json:"date,omitempty"`
+	// RequiredVotes, when positive, adds a footer showing the votes cast
+	// against the number the playtest needs.
+	RequiredVotes int `json:"required_votes,omitempty"`
 }
 
-func ParseAndValidate(r *http.Request) (map[string]int, error) {
+const dateLayout = "2006-01-02"
+
+func (req *ChartRequest) input() voteInput {
+	return voteInput{votes: req.Votes, ballots: req.Ballots, groups: req.Groups}
+}
+
+func (req *ChartRequest) aggregation() chart.Aggregation {
+	return chart.Aggregation{
+		Mode:        chart.AggregationMode(req.Aggregation),
+		Prior:       req.SubmittedDifficulty,
+		PriorWeight: req.PriorWeight,
+	}
+}
+
+func (req *ChartRequest) mapInfo() (chart.MapInfo, error) {
+	info := chart.MapInfo{
+		Code:     req.MapCode,
+		Name:     req.MapName,
+		Creator:  req.Creator,
+		ThreadID: req.ThreadID,
+	}
+	if req.Date != "" {
+		date, err := time.Parse(dateLayout, req.Date)
+		if err != nil {
+			return chart.MapInfo{}, fmt.Errorf("invalid date: %s (want YYYY-MM-DD)", req.Date)
+		}
+		info.Date = date
+	}
+	return info, nil
+}
+
+func ParseAndValidate(r *http.Request) (*ChartRequest, error) {
 	var req ChartRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, errors.New("invalid JSON")
 	}
 
-	if req.Votes == nil {
-		return nil, errors.New("missing votes field")
+	sc, err := chart.LookupScale(req.DifficultyScale)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.input().validate(sc); err != nil {
+		return nil, err
+	}
+
+	if req.PreviousVotes != nil {
+		if req.Votes == nil {
+			return nil, errors.New("previous_votes can only be compared with votes")
+		}
+		if err := validateVotes(sc, req.PreviousVotes); err != nil {
+			return nil, fmt.Errorf("previous_votes: %w", err)
+		}
+	}
+
+	if err := chart.ValidateSize(req.Width, req.Height, req.Scale); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupTheme(req.Theme); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupPalette(req.Palette); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.ParseColorOverrides(sc, req.Colors); err != nil {
+		return nil, err
+	}
+
+	if err := req.aggregation().Validate(sc); err != nil {
+		return nil, err
+	}
+
+	if req.OfficialDifficulty != "" {
+		if _, ok := sc.Index(req.OfficialDifficulty); !ok {
+			return nil, fmt.Errorf("invalid official difficulty: %s", req.OfficialDifficulty)
+		}
+	}
+
+	if _, err := chart.LookupOrientation(req.Orientation); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupChartType(req.Type); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupYAxis(req.YAxis); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupBarLabel(req.BarLabels); err != nil {
+		return nil, err
+	}
+
+	if _, err := req.mapInfo(); err != nil {
+		return nil, err
+	}
+
+	if err := chart.ValidateRequiredVotes(req.RequiredVotes); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func validateVotes(sc *chart.Scale, votes map[string]int) error {
+	if votes == nil {
+		return errors.New("missing votes field")
 	}
 
 	totalVotes := 0
-	for level, count := range req.Votes {
-		if _, ok := chart.DifficultyIndex(level); !ok {
-			return nil, fmt.Errorf("invalid difficulty: %s", level)
+	for level, count := range votes {
+		if _, ok := sc.Index(level); !ok {
+			return fmt.Errorf("invalid difficulty: %s", level)
 		}
 		if count < 0 {
-			return nil, fmt.Errorf("invalid vote count for %s", level)
+			return fmt.Errorf("invalid vote count for %s", level)
 		}
 		totalVotes += count
 	}
 
 	if totalVotes == 0 {
-		return nil, errors.New("no votes provided")
+		return errors.New("no votes provided")
 	}
 
-	return req.Votes, nil
+	return nil
 }
 
 func ChartHandler(w http.ResponseWriter, r *http.Request) {
@@ -48,19 +203,60 @@ func ChartHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	votes, err := ParseAndValidate(r)
+	req, err := ParseAndValidate(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	imgData, err := chart.RenderChart(votes)
+	format, err := negotiateFormat(r, req.Format)
+	if err != nil {
+		writeError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
+	info, _ := req.mapInfo()
+	opts := chart.Options{
+		Format:              format,
+		Width:               req.Width,
+		Height:              req.Height,
+		Scale:               req.Scale,
+		Theme:               req.Theme,
+		Palette:             req.Palette,
+		Patterns:            req.Patterns,
+		Colors:              req.Colors,
+		DifficultyScale:     req.DifficultyScale,
+		ShowMedian:          req.ShowMedian,
+		ShowIQR:             req.ShowIQR,
+		ShowAgreement:       req.ShowAgreement,
+		ShowConfidence:      req.ShowConfidence,
+		Aggregation:         req.aggregation(),
+		Map:                 info,
+		Type:                chart.ChartType(req.Type),
+		Orientation:         chart.Orientation(req.Orientation),
+		YAxis:               chart.YAxis(req.YAxis),
+		BarLabels:           chart.BarLabel(req.BarLabels),
+		RequiredVotes:       req.RequiredVotes,
+		VotesCast:           req.input().count(),
+		SampleSize:          req.input().sampleSize(),
+		SubmittedDifficulty: req.SubmittedDifficulty,
+		OfficialDifficulty:  req.OfficialDifficulty,
+	}
+	var imgData []byte
+	if groups := req.input().chartGroups(); groups != nil {
+		imgData, err = chart.RenderGroups(groups, opts)
+	} else if req.PreviousVotes != nil {
+		imgData, err = chart.RenderComparison(chart.TallyCounts(req.PreviousVotes), req.input().tally(), opts)
+	} else {
+		imgData, err = chart.RenderTally(req.input().tally(), opts)
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to generate chart")
 		return
 	}
 
-	w.Header().Set("Content-Type", "image/webp")
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Vary", "Accept")
 	w.WriteHeader(http.StatusOK)
 	w.Write(imgData)
 }
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/genjishimada/playtest-plotter/chart"
 )
 
 func TestValidateRequest(t *testing.T) {
@@ -50,6 +52,197 @@ func TestValidateRequest(t *testing.T) {
 			wantErr:    true,
 			errContain: "invalid vote count",
 		},
+		{
+			name:    "custom size and scale",
+			body:    `{"votes":{"Easy":5},"width":400,"height":200,"scale":2}`,
+			wantErr: false,
+		},
+		{
+			name:       "width out of range",
+			body:       `{"votes":{"Easy":5},"width":50}`,
+			wantErr:    true,
+			errContain: "width must be between",
+		},
+		{
+			name:       "scale out of range",
+			body:       `{"votes":{"Easy":5},"scale":10}`,
+			wantErr:    true,
+			errContain: "scale must be between",
+		},
+		{
+			name:    "light theme",
+			body:    `{"votes":{"Easy":5},"theme":"light"}`,
+			wantErr: false,
+		},
+		{
+			name:       "unknown theme",
+			body:       `{"votes":{"Easy":5},"theme":"neon"}`,
+			wantErr:    true,
+			errContain: "unknown theme",
+		},
+		{
+			name:    "palette with patterns",
+			body:    `{"votes":{"Easy":5},"palette":"deuteranopia","patterns":true}`,
+			wantErr: false,
+		},
+		{
+			name:       "unknown palette",
+			body:       `{"votes":{"Easy":5},"palette":"rainbow"}`,
+			wantErr:    true,
+			errContain: "unknown palette",
+		},
+		{
+			name:    "color overrides",
+			body:    `{"votes":{"Easy":5},"colors":{"Easy":"#0f0","background":"#00000000","text":"#FFEEDD"}}`,
+			wantErr: false,
+		},
+		{
+			name:       "invalid override color",
+			body:       `{"votes":{"Easy":5},"colors":{"Easy":"#0f0","Hard":"red"}}`,
+			wantErr:    true,
+			errContain: `colors["Hard"]`,
+		},
+		{
+			name:       "unknown override key",
+			body:       `{"votes":{"Easy":5},"colors":{"border":"#000"}}`,
+			wantErr:    true,
+			errContain: `unknown key "border"`,
+		},
+		{
+			name:       "unknown difficulty scale",
+			body:       `{"votes":{"Easy":5},"difficulty_scale":"arena"}`,
+			wantErr:    true,
+			errContain: "unknown scale",
+		},
+		{
+			name:    "trimmed aggregation",
+			body:    `{"votes":{"Medium":8,"Hell":2},"aggregation":"trimmed"}`,
+			wantErr: false,
+		},
+		{
+			name:    "bayesian aggregation",
+			body:    `{"votes":{"Medium":8,"Hell":2},"aggregation":"bayesian","submitted_difficulty":"Medium","prior_weight":3}`,
+			wantErr: false,
+		},
+		{
+			name:       "unknown aggregation",
+			body:       `{"votes":{"Easy":5},"aggregation":"mode"}`,
+			wantErr:    true,
+			errContain: "unknown aggregation",
+		},
+		{
+			name:       "bayesian without submitted difficulty",
+			body:       `{"votes":{"Easy":5},"aggregation":"bayesian"}`,
+			wantErr:    true,
+			errContain: "needs a submitted difficulty",
+		},
+		{
+			name:       "invalid submitted difficulty",
+			body:       `{"votes":{"Easy":5},"aggregation":"bayesian","submitted_difficulty":"Medium ++"}`,
+			wantErr:    true,
+			errContain: "invalid submitted difficulty",
+		},
+		{
+			name:       "negative prior weight",
+			body:       `{"votes":{"Easy":5},"aggregation":"bayesian","submitted_difficulty":"Easy","prior_weight":-1}`,
+			wantErr:    true,
+			errContain: "prior weight",
+		},
+		{
+			name:    "horizontal orientation",
+			body:    `{"votes":{"Very Hard +":3},"orientation":"horizontal"}`,
+			wantErr: false,
+		},
+		{
+			name:       "unknown orientation",
+			body:       `{"votes":{"Hard":3},"orientation":"diagonal"}`,
+			wantErr:    true,
+			errContain: "unknown orientation",
+		},
+		{
+			name:    "donut",
+			body:    `{"votes":{"Hard":3,"Hell":1},"type":"donut","width":400,"height":400}`,
+			wantErr: false,
+		},
+		{
+			name:       "unknown chart type",
+			body:       `{"votes":{"Hard":3},"type":"radar"}`,
+			wantErr:    true,
+			errContain: "unknown chart type",
+		},
+		{
+			name:    "map metadata",
+			body:    `{"votes":{"Hard":3},"map_code":"KX7PQ","map_name":"Temple","creator":"genji","thread_id":"1234567890","date":"2026-03-01"}`,
+			wantErr: false,
+		},
+		{
+			name:       "invalid date",
+			body:       `{"votes":{"Hard":3},"date":"03/01/2026"}`,
+			wantErr:    true,
+			errContain: "invalid date",
+		},
+		{
+			name:    "submitted and official difficulty",
+			body:    `{"votes":{"Hard":3},"submitted_difficulty":"Medium","official_difficulty":"Hard +"}`,
+			wantErr: false,
+		},
+		{
+			name:       "invalid official difficulty",
+			body:       `{"votes":{"Hard":3},"official_difficulty":"Hard ++"}`,
+			wantErr:    true,
+			errContain: "invalid official difficulty",
+		},
+		{
+			name:    "percent y axis",
+			body:    `{"votes":{"Hard":3},"y_axis":"percent","bar_labels":"both"}`,
+			wantErr: false,
+		},
+		{
+			name:       "unknown y axis",
+			body:       `{"votes":{"Hard":3},"y_axis":"log"}`,
+			wantErr:    true,
+			errContain: "unknown y axis",
+		},
+		{
+			name:       "unknown bar label",
+			body:       `{"votes":{"Hard":3},"bar_labels":"votes"}`,
+			wantErr:    true,
+			errContain: "unknown bar label",
+		},
+		{
+			name:    "required votes",
+			body:    `{"votes":{"Hard":3},"required_votes":10}`,
+			wantErr: false,
+		},
+		{
+			name:       "negative required votes",
+			body:       `{"votes":{"Hard":3},"required_votes":-1}`,
+			wantErr:    true,
+			errContain: "required votes must not be negative",
+		},
+		{
+			name:    "previous votes",
+			body:    `{"votes":{"Hard":4},"previous_votes":{"Medium":3,"Medium +":2}}`,
+			wantErr: false,
+		},
+		{
+			name:       "invalid previous votes",
+			body:       `{"votes":{"Hard":4},"previous_votes":{"Medium ++":3}}`,
+			wantErr:    true,
+			errContain: "previous_votes: invalid difficulty",
+		},
+		{
+			name:       "empty previous votes",
+			body:       `{"votes":{"Hard":4},"previous_votes":{}}`,
+			wantErr:    true,
+			errContain: "previous_votes: no votes provided",
+		},
+		{
+			name:       "previous votes with groups",
+			body:       `{"groups":[{"name":"a","votes":{"Hard":1}}],"previous_votes":{"Hard":1}}`,
+			wantErr:    true,
+			errContain: "only be compared with votes",
+		},
 	}
 
 	for _, tt := range tests {
@@ -57,7 +250,7 @@ func TestValidateRequest(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(tt.body))
 			req.Header.Set("Content-Type", "application/json")
 
-			votes, err := ParseAndValidate(req)
+			parsed, err := ParseAndValidate(req)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -71,7 +264,7 @@ func TestValidateRequest(t *testing.T) {
 					t.Errorf("unexpected error: %v", err)
 					return
 				}
-				if len(votes) == 0 {
+				if len(parsed.Votes) == 0 {
 					t.Error("expected votes, got empty map")
 				}
 			}
@@ -79,11 +272,41 @@ func TestValidateRequest(t *testing.T) {
 	}
 }
 
+func TestValidateRequestCustomScale(t *testing.T) {
+	chart.RegisterScale(chart.NewScale("tiers",
+		[]string{"Low", "High"},
+		map[string]float64{"Low": 1, "High": 3},
+		map[string]chart.DifficultyRange{"Low": {Lower: 0, Upper: 2}, "High": {Lower: 2, Upper: 4, UpperInclusive: true}},
+		nil,
+	))
+
+	parse := func(body string) error {
+		req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(body))
+		_, err := ParseAndValidate(req)
+		return err
+	}
+
+	if err := parse(`{"votes":{"Low":2,"High":1},"difficulty_scale":"tiers","colors":{"High":"#f00"}}`); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := parse(`{"votes":{"Easy":2},"difficulty_scale":"tiers"}`); err == nil || !contains(err.Error(), "invalid difficulty: Easy") {
+		t.Errorf("error = %v, want invalid difficulty", err)
+	}
+}
+
 func contains(s, substr string) bool {
 	return bytes.Contains([]byte(s), []byte(substr))
 }
 
+func requireContentType(t *testing.T, contentType string) {
+	t.Helper()
+	if _, ok := chart.ParseFormat(contentType); !ok {
+		t.Skipf("%s output is not available in this build", contentType)
+	}
+}
+
 func TestChartHandler(t *testing.T) {
+	requireContentType(t, "image/webp")
 	body := `{"votes":{"Medium":10,"Medium +":5}}`
 	req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -124,3 +347,104 @@ func TestChartHandlerError(t *testing.T) {
 		t.Error("expected error field in response")
 	}
 }
+
+func TestChartHandlerFormatNegotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{
+			name:        "no preference defaults to webp",
+			body:        `{"votes":{"Medium":10}}`,
+			status:      http.StatusOK,
+			contentType: "image/webp",
+		},
+		{
+			name:        "accept png",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/png",
+			status:      http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "accept highest quality wins",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/webp;q=0.5, image/png;q=0.9",
+			status:      http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "accept svg",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/svg+xml",
+			status:      http.StatusOK,
+			contentType: "image/svg+xml",
+		},
+		{
+			name:        "wildcard uses default",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/avif, */*;q=0.1",
+			status:      http.StatusOK,
+			contentType: "image/webp",
+		},
+		{
+			name:        "wildcard skips excluded default",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/webp;q=0, */*",
+			status:      http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "wildcard with every format excluded",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/webp;q=0, image/png;q=0, image/svg+xml;q=0, image/*",
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json",
+		},
+		{
+			name:        "format field overrides accept",
+			body:        `{"votes":{"Medium":10},"format":"png"}`,
+			accept:      "image/webp",
+			status:      http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:        "unsupported accept",
+			body:        `{"votes":{"Medium":10}}`,
+			accept:      "image/avif",
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json",
+		},
+		{
+			name:        "unsupported format field",
+			body:        `{"votes":{"Medium":10},"format":"gif"}`,
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.status == http.StatusOK {
+				requireContentType(t, tt.contentType)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(tt.body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			ChartHandler(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("handler returned wrong status: got %d want %d", rr.Code, tt.status)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("wrong content type: got %s want %s", got, tt.contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiateFormat picks the output format for a chart. An explicit format
+// from the request body wins; otherwise the Accept header is consulted, and
+// an absent header falls back to chart.DefaultFormat.
+func negotiateFormat(r *http.Request, requested string) (chart.Format, error) {
+	if requested != "" {
+		if f, ok := chart.ParseFormat(requested); ok {
+			return f, nil
+		}
+		return "", fmt.Errorf("unsupported format: %s (supported: %s)", requested, supportedFormats())
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return chart.DefaultFormat(), nil
+	}
+
+	ranges, excluded := parseAccept(accept)
+	for _, ar := range ranges {
+		switch ar.mediaType {
+		case "*/*", "image/*":
+			if f, ok := wildcardFormat(excluded); ok {
+				return f, nil
+			}
+			continue
+		}
+		if f, ok := chart.ParseFormat(ar.mediaType); ok && !excluded[f] {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("no supported format in Accept header: %s (supported: %s)", accept, supportedFormats())
+}
+
+// wildcardFormat resolves a wildcard media range to chart.DefaultFormat, or
+// to the first other format when the client excluded the default.
+func wildcardFormat(excluded map[chart.Format]bool) (chart.Format, bool) {
+	if f := chart.DefaultFormat(); !excluded[f] {
+		return f, true
+	}
+	for _, f := range chart.Formats() {
+		if !excluded[f] {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+func supportedFormats() string {
+	names := make([]string, 0, len(chart.Formats()))
+	for _, f := range chart.Formats() {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseAccept returns the acceptable media ranges ordered by descending
+// quality. Ranges with q=0 are dropped, and the formats they name are
+// returned as excluded.
+func parseAccept(header string) ([]acceptRange, map[chart.Format]bool) {
+	var ranges []acceptRange
+	excluded := make(map[chart.Format]bool)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+		if q <= 0 {
+			if f, ok := chart.ParseFormat(mediaType); ok {
+				excluded[f] = true
+			}
+			continue
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	return ranges, excluded
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+const maxReportMaps = 100
+
+type ReportRequest struct {
+	Maps []ReportMapRequest `json:"maps"`
+}
+
+type ReportMapRequest struct {
+	Code    string         `json:"code"`
+	Title   string         `json:"title,omitempty"`
+	Creator string         `json:"creator,omitempty"`
+	Votes   map[string]int `json:"votes"`
+}
+
+func ParseReport(r *http.Request) (*ReportRequest, error) {
+	var req ReportRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.New("invalid JSON")
+	}
+
+	if len(req.Maps) == 0 {
+		return nil, errors.New("no maps provided")
+	}
+	if len(req.Maps) > maxReportMaps {
+		return nil, fmt.Errorf("too many maps: %d (max %d)", len(req.Maps), maxReportMaps)
+	}
+
+	for i, m := range req.Maps {
+		if m.Code == "" {
+			return nil, fmt.Errorf("maps[%d]: missing code", i)
+		}
+		if err := validateVotes(chart.GenjiScale, m.Votes); err != nil {
+			return nil, fmt.Errorf("maps[%d] (%s): %w", i, m.Code, err)
+		}
+	}
+
+	return &req, nil
+}
+
+func ReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	req, err := ParseReport(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	maps := make([]chart.ReportMap, len(req.Maps))
+	for i, m := range req.Maps {
+		maps[i] = chart.ReportMap{
+			Code:    m.Code,
+			Title:   m.Title,
+			Creator: m.Creator,
+			Votes:   m.Votes,
+		}
+	}
+
+	pdfData, err := chart.RenderReport(maps)
+	if errors.Is(err, chart.ErrReportUnsupported) {
+		writeError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="playtest-report.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfData)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    bool
+		errContain string
+	}{
+		{
+			name: "valid request",
+			body: `{"maps":[{"code":"ABC12","title":"Temple","creator":"genji","votes":{"Hard":3}}]}`,
+		},
+		{
+			name:       "invalid json",
+			body:       `{not json}`,
+			wantErr:    true,
+			errContain: "invalid JSON",
+		},
+		{
+			name:       "no maps",
+			body:       `{"maps":[]}`,
+			wantErr:    true,
+			errContain: "no maps",
+		},
+		{
+			name:       "missing code",
+			body:       `{"maps":[{"votes":{"Hard":3}}]}`,
+			wantErr:    true,
+			errContain: "maps[0]: missing code",
+		},
+		{
+			name:       "invalid votes named by map",
+			body:       `{"maps":[{"code":"ABC12","votes":{"Hard":3}},{"code":"XYZ99","votes":{"NotReal":1}}]}`,
+			wantErr:    true,
+			errContain: "maps[1] (XYZ99): invalid difficulty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/report", bytes.NewBufferString(tt.body))
+
+			parsed, err := ParseReport(req)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errContain) {
+					t.Errorf("error %q should contain %q", err.Error(), tt.errContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(parsed.Maps) != 1 {
+				t.Errorf("expected 1 map, got %d", len(parsed.Maps))
+			}
+		})
+	}
+}
+
+func TestReportHandler(t *testing.T) {
+	body := `{"maps":[
+		{"code":"ABC12","title":"Temple","creator":"genji","votes":{"Hard":3,"Hard +":2}},
+		{"code":"XYZ99","votes":{"Medium":5}}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/report", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	ReportHandler(rr, req)
+
+	if rr.Code == http.StatusNotImplemented {
+		t.Skip("PDF reports are not available in this build")
+	}
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("wrong content type: got %s want application/pdf", ct)
+	}
+	if !bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF")) {
+		t.Error("expected PDF document")
+	}
+}
+
+func TestReportHandlerError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/report", bytes.NewBufferString(`{"maps":[]}`))
+
+	rr := httptest.NewRecorder()
+	ReportHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status: got %d want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	var errResp map[string]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("failed to parse error response: %v", err)
+	}
+	if _, ok := errResp["error"]; !ok {
+		t.Error("expected error field in response")
+	}
+}
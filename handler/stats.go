@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+type StatsRequest struct {
+	Votes           map[string]int `json:"votes"`
+	Ballots         []Ballot       `json:"ballots,omitempty"`
+	Groups          []VoteGroup    `json:"groups,omitempty"`
+	DifficultyScale string         `json:"difficulty_scale,omitempty"`
+	// RequiredVotes, when positive, adds the progress toward that many
+	// votes to the response.
+	RequiredVotes int `json:"required_votes,omitempty"`
+
+	// scale is the scale DifficultyScale names, resolved by ParseStats.
+	scale *chart.Scale
+}
+
+type StatsResponse struct {
+	TotalVotes  float64  `json:"total_votes"`
+	Mean        float64  `json:"mean"`
+	MeanLevel   string   `json:"mean_level"`
+	Median      float64  `json:"median"`
+	MedianLevel string   `json:"median_level"`
+	Modes       []string `json:"modes"`
+	Peaks       []string `json:"peaks"`
+	Multimodal  bool     `json:"multimodal"`
+	// AverageInValley is set when mean_level is a thinly voted level
+	// between two peaks.
+	AverageInValley bool    `json:"average_in_valley"`
+	StdDev          float64 `json:"std_dev"`
+	Q1              float64 `json:"q1"`
+	Q3              float64 `json:"q3"`
+	IQR             float64 `json:"iqr"`
+	// CILow and CIHigh bound the 95% confidence interval for the mean.
+	CILow       float64      `json:"ci_low"`
+	CIHigh      float64      `json:"ci_high"`
+	CILowLevel  string       `json:"ci_low_level"`
+	CIHighLevel string       `json:"ci_high_level"`
+	Agreement   float64      `json:"agreement"`
+	Consensus   string       `json:"consensus"`
+	Shares      []LevelShare `json:"shares"`
+	// Progress is set when the request gives required_votes.
+	Progress *VoteProgress `json:"progress,omitempty"`
+}
+
+// VoteProgress is the progress of the votes toward the required count; see
+// chart.VoteProgress.
+type VoteProgress struct {
+	RequiredVotes int     `json:"required_votes"`
+	TotalVotes    float64 `json:"total_votes"`
+	Remaining     float64 `json:"remaining"`
+	Percent       float64 `json:"percent"`
+	Reached       bool    `json:"reached"`
+}
+
+type LevelShare struct {
+	Level   string  `json:"level"`
+	Votes   float64 `json:"votes"`
+	Percent float64 `json:"percent"`
+}
+
+func (req *StatsRequest) input() voteInput {
+	return voteInput{votes: req.Votes, ballots: req.Ballots, groups: req.Groups}
+}
+
+func ParseStats(r *http.Request) (*StatsRequest, error) {
+	var req StatsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.New("invalid JSON")
+	}
+
+	sc, err := chart.LookupScale(req.DifficultyScale)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.input().validate(sc); err != nil {
+		return nil, err
+	}
+
+	if err := chart.ValidateRequiredVotes(req.RequiredVotes); err != nil {
+		return nil, err
+	}
+
+	req.scale = sc
+	return &req, nil
+}
+
+func newStatsResponse(st chart.Stats) StatsResponse {
+	shares := make([]LevelShare, len(st.Shares))
+	for i, sh := range st.Shares {
+		shares[i] = LevelShare{Level: sh.Level, Votes: sh.Votes, Percent: sh.Percent}
+	}
+	return StatsResponse{
+		TotalVotes:      st.TotalVotes,
+		Mean:            st.Mean,
+		MeanLevel:       st.MeanLevel,
+		Median:          st.Median,
+		MedianLevel:     st.MedianLevel,
+		Modes:           st.Modes,
+		Peaks:           st.Peaks,
+		Multimodal:      st.Multimodal,
+		AverageInValley: st.AverageInValley,
+		StdDev:          st.StdDev,
+		Q1:              st.Q1,
+		Q3:              st.Q3,
+		IQR:             st.IQR,
+		CILow:           st.CILow,
+		CIHigh:          st.CIHigh,
+		CILowLevel:      st.CILowLevel,
+		CIHighLevel:     st.CIHighLevel,
+		Agreement:       st.Agreement,
+		Consensus:       string(st.Consensus),
+		Shares:          shares,
+	}
+}
+
+func StatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	req, err := ParseStats(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	in := req.input()
+	resp := newStatsResponse(req.scale.SampleStats(in.tally(), in.sampleSize()))
+	if req.RequiredVotes > 0 {
+		p := chart.VoteProgress(float64(in.count()), req.RequiredVotes)
+		resp.Progress = &VoteProgress{
+			RequiredVotes: p.Required,
+			TotalVotes:    p.Votes,
+			Remaining:     p.Remaining,
+			Percent:       p.Percent,
+			Reached:       p.Reached,
+		}
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    bool
+		errContain string
+	}{
+		{name: "valid request", body: `{"votes":{"Hard":3,"Easy":1}}`},
+		{name: "invalid json", body: `{not json}`, wantErr: true, errContain: "invalid JSON"},
+		{name: "missing votes", body: `{}`, wantErr: true, errContain: "missing votes"},
+		{name: "invalid difficulty", body: `{"votes":{"NotReal":1}}`, wantErr: true, errContain: "invalid difficulty"},
+		{name: "unknown scale", body: `{"votes":{"Hard":1},"difficulty_scale":"nope"}`, wantErr: true, errContain: "unknown scale"},
+		{name: "required votes", body: `{"votes":{"Hard":1},"required_votes":5}`},
+		{name: "negative required votes", body: `{"votes":{"Hard":1},"required_votes":-1}`, wantErr: true, errContain: "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/stats", bytes.NewBufferString(tt.body))
+
+			got, err := ParseStats(req)
+			if tt.wantErr {
+				if err == nil || !contains(err.Error(), tt.errContain) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.errContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.scale == nil {
+				t.Error("ParseStats() did not resolve the scale")
+			}
+		})
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	body := `{"votes":{"Easy":3,"Hard":1,"Extreme":3}}`
+	req := httptest.NewRequest(http.MethodPost, "/stats", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	StatsHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("wrong content type: got %s want application/json", ct)
+	}
+
+	var resp StatsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.TotalVotes != 7 {
+		t.Errorf("total_votes = %v, want 7", resp.TotalVotes)
+	}
+	if resp.Median != 5.0 || resp.MedianLevel != "Hard" {
+		t.Errorf("median = %v (%s), want 5 (Hard)", resp.Median, resp.MedianLevel)
+	}
+	if len(resp.Modes) != 2 || resp.Modes[0] != "Easy" || resp.Modes[1] != "Extreme" {
+		t.Errorf("modes = %v, want [Easy Extreme]", resp.Modes)
+	}
+	if !resp.Multimodal || len(resp.Peaks) != 2 || !resp.AverageInValley {
+		t.Errorf("peaks = %v, multimodal = %v, average_in_valley = %v", resp.Peaks, resp.Multimodal, resp.AverageInValley)
+	}
+	if resp.CILow >= resp.Mean || resp.CIHigh <= resp.Mean || resp.CILowLevel == "" || resp.CIHighLevel == "" {
+		t.Errorf("confidence interval = [%v (%s), %v (%s)] around mean %v", resp.CILow, resp.CILowLevel, resp.CIHigh, resp.CIHighLevel, resp.Mean)
+	}
+	if resp.Consensus != "split" || resp.Agreement <= 0 || resp.Agreement >= 0.6 {
+		t.Errorf("agreement = %v (%s), want split", resp.Agreement, resp.Consensus)
+	}
+	if len(resp.Shares) != 16 || resp.Shares[1].Level != "Easy" || resp.Shares[1].Votes != 3 {
+		t.Errorf("shares = %+v", resp.Shares)
+	}
+	if resp.Progress != nil {
+		t.Errorf("progress = %+v without required_votes", resp.Progress)
+	}
+}
+
+func TestStatsHandlerProgress(t *testing.T) {
+	tests := []struct {
+		body string
+		want VoteProgress
+	}{
+		{`{"votes":{"Hard":3,"Easy":1},"required_votes":10}`, VoteProgress{RequiredVotes: 10, TotalVotes: 4, Remaining: 6, Percent: 40}},
+		{`{"votes":{"Hard":3,"Easy":1},"required_votes":4}`, VoteProgress{RequiredVotes: 4, TotalVotes: 4, Percent: 100, Reached: true}},
+		// Progress counts ballots, whatever their weight.
+		{`{"ballots":[{"voter":"a","difficulty":"Hard","weight":3},{"voter":"b","difficulty":"Hard","weight":3},{"voter":"c","difficulty":"Easy","weight":3}],"required_votes":6}`, VoteProgress{RequiredVotes: 6, TotalVotes: 3, Remaining: 3, Percent: 50}},
+		{`{"ballots":[{"voter":"a","difficulty":"Hard","weight":0.5},{"voter":"b","difficulty":"Easy","weight":0.5}],"required_votes":2}`, VoteProgress{RequiredVotes: 2, TotalVotes: 2, Percent: 100, Reached: true}},
+		{`{"groups":[{"name":"vets","votes":{"Hard":2}},{"name":"new","votes":{"Easy":1}}],"required_votes":6}`, VoteProgress{RequiredVotes: 6, TotalVotes: 3, Remaining: 3, Percent: 50}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/stats", bytes.NewBufferString(tt.body))
+		rr := httptest.NewRecorder()
+		StatsHandler(rr, req)
+
+		var resp StatsResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+		if resp.Progress == nil || *resp.Progress != tt.want {
+			t.Errorf("%s: progress = %+v, want %+v", tt.body, resp.Progress, tt.want)
+		}
+	}
+}
+
+func TestStatsHandlerMethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+
+	rr := httptest.NewRecorder()
+	StatsHandler(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status: got %d want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+const maxTimelineVotes = 10000
+
+type TimelineRequest struct {
+	Votes []TimedVote `json:"votes"`
+	// StabilityVotes is the number of latest votes the stability indicator
+	// measures; zero selects chart.DefaultStabilityVotes.
+	StabilityVotes  int               `json:"stability_votes,omitempty"`
+	Format          string            `json:"format,omitempty"`
+	Width           int               `json:"width,omitempty"`
+	Height          int               `json:"height,omitempty"`
+	Scale           float64           `json:"scale,omitempty"`
+	Theme           string            `json:"theme,omitempty"`
+	Palette         string            `json:"palette,omitempty"`
+	Colors          map[string]string `json:"colors,omitempty"`
+	DifficultyScale string            `json:"difficulty_scale,omitempty"`
+	Aggregation     string            `json:"aggregation,omitempty"`
+	// SubmittedDifficulty and PriorWeight configure the "bayesian"
+	// aggregation as for ChartRequest.
+	SubmittedDifficulty string  `json:"submitted_difficulty,omitempty"`
+	PriorWeight         float64 `json:"prior_weight,omitempty"`
+}
+
+// TimedVote is one vote with the time it was cast, as an RFC 3339 string.
+type TimedVote struct {
+	Time       time.Time `json:"time"`
+	Difficulty string    `json:"difficulty"`
+	// Weight defaults to 1 when omitted or zero.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+func (req *TimelineRequest) aggregation() chart.Aggregation {
+	return chart.Aggregation{
+		Mode:        chart.AggregationMode(req.Aggregation),
+		Prior:       req.SubmittedDifficulty,
+		PriorWeight: req.PriorWeight,
+	}
+}
+
+func ParseTimeline(r *http.Request) (*TimelineRequest, error) {
+	var req TimelineRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.New("invalid JSON")
+	}
+
+	sc, err := chart.LookupScale(req.DifficultyScale)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Votes) == 0 {
+		return nil, errors.New("no votes provided")
+	}
+	if len(req.Votes) > maxTimelineVotes {
+		return nil, fmt.Errorf("too many votes: %d (max %d)", len(req.Votes), maxTimelineVotes)
+	}
+	for i, v := range req.Votes {
+		if v.Time.IsZero() {
+			return nil, fmt.Errorf("votes[%d]: missing time", i)
+		}
+		if _, ok := sc.Index(v.Difficulty); !ok {
+			return nil, fmt.Errorf("votes[%d]: invalid difficulty: %s", i, v.Difficulty)
+		}
+		if err := chart.ValidateWeight(v.Weight); err != nil {
+			return nil, fmt.Errorf("votes[%d]: invalid weight: %w", i, err)
+		}
+	}
+
+	if err := chart.ValidateTimelineSpan(req.chartVotes()); err != nil {
+		return nil, err
+	}
+
+	if req.StabilityVotes < 0 {
+		return nil, errors.New("stability_votes must not be negative")
+	}
+
+	if err := chart.ValidateSize(req.Width, req.Height, req.Scale); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupTheme(req.Theme); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.LookupPalette(req.Palette); err != nil {
+		return nil, err
+	}
+
+	if _, err := chart.ParseColorOverrides(sc, req.Colors); err != nil {
+		return nil, err
+	}
+
+	if err := req.aggregation().Validate(sc); err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// chartVotes converts the votes, applying the default weight.
+func (req *TimelineRequest) chartVotes() []chart.TimedVote {
+	votes := make([]chart.TimedVote, len(req.Votes))
+	for i, v := range req.Votes {
+		weight := v.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		votes[i] = chart.TimedVote{Time: v.Time, Level: v.Difficulty, Weight: weight}
+	}
+	return votes
+}
+
+func TimelineHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	req, err := ParseTimeline(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format, err := negotiateFormat(r, req.Format)
+	if err != nil {
+		writeError(w, http.StatusNotAcceptable, err.Error())
+		return
+	}
+
+	imgData, err := chart.RenderTimeline(req.chartVotes(), chart.Options{
+		Format:          format,
+		Width:           req.Width,
+		Height:          req.Height,
+		Scale:           req.Scale,
+		Theme:           req.Theme,
+		Palette:         req.Palette,
+		Colors:          req.Colors,
+		DifficultyScale: req.DifficultyScale,
+		Aggregation:     req.aggregation(),
+		StabilityVotes:  req.StabilityVotes,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to generate chart")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+	w.Write(imgData)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTimeline(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    bool
+		errContain string
+	}{
+		{
+			name: "valid request",
+			body: `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard"},{"time":"2026-03-01T12:00:00Z","difficulty":"Hard +","weight":2}],"stability_votes":3}`,
+		},
+		{
+			name:       "invalid json",
+			body:       `{not json}`,
+			wantErr:    true,
+			errContain: "invalid JSON",
+		},
+		{
+			name:       "malformed time",
+			body:       `{"votes":[{"time":"yesterday","difficulty":"Hard"}]}`,
+			wantErr:    true,
+			errContain: "invalid JSON",
+		},
+		{
+			name:       "no votes",
+			body:       `{"votes":[]}`,
+			wantErr:    true,
+			errContain: "no votes provided",
+		},
+		{
+			name:       "missing time",
+			body:       `{"votes":[{"difficulty":"Hard"}]}`,
+			wantErr:    true,
+			errContain: "votes[0]: missing time",
+		},
+		{
+			name:       "invalid difficulty",
+			body:       `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard"},{"time":"2026-03-01T10:00:00Z","difficulty":"Harder"}]}`,
+			wantErr:    true,
+			errContain: "votes[1]: invalid difficulty: Harder",
+		},
+		{
+			name:       "negative weight",
+			body:       `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard","weight":-1}]}`,
+			wantErr:    true,
+			errContain: "votes[0]: invalid weight",
+		},
+		{
+			name:       "huge weight",
+			body:       `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard","weight":1e308}]}`,
+			wantErr:    true,
+			errContain: "votes[0]: invalid weight: weight must be a number from 0 to 1000",
+		},
+		{
+			name:       "span too long",
+			body:       `{"votes":[{"time":"0001-01-02T00:00:00Z","difficulty":"Hard"},{"time":"9999-12-31T00:00:00Z","difficulty":"Hard"}]}`,
+			wantErr:    true,
+			errContain: "votes span more than",
+		},
+		{
+			name:       "negative stability votes",
+			body:       `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard"}],"stability_votes":-2}`,
+			wantErr:    true,
+			errContain: "stability_votes",
+		},
+		{
+			name:       "unknown theme",
+			body:       `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard"}],"theme":"neon"}`,
+			wantErr:    true,
+			errContain: "unknown theme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/timeline", bytes.NewBufferString(tt.body))
+
+			_, err := ParseTimeline(req)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errContain) {
+					t.Errorf("error %q should contain %q", err.Error(), tt.errContain)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTimelineHandler(t *testing.T) {
+	body := `{"votes":[{"time":"2026-03-01T09:00:00Z","difficulty":"Hard"},{"time":"2026-03-02T09:00:00Z","difficulty":"Hard -"}],"format":"png"}`
+	req := httptest.NewRequest(http.MethodPost, "/timeline", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	TimelineHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("wrong content type: got %s want image/png", ct)
+	}
+}
+
+func TestTimelineHandlerMethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/timeline", nil)
+	rr := httptest.NewRecorder()
+	TimelineHandler(rr, req)
+
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("handler returned wrong status: got %d want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+// VoteGroup is the votes of one group of voters in the grouped request
+// shape.
+type VoteGroup struct {
+	Name  string         `json:"name"`
+	Votes map[string]int `json:"votes"`
+}
+
+// voteInput holds the request shapes votes can arrive in: a votes map,
+// weighted ballots or per-group votes. Exactly one may be given.
+type voteInput struct {
+	votes   map[string]int
+	ballots []Ballot
+	groups  []VoteGroup
+}
+
+func (in voteInput) validate(sc *chart.Scale) error {
+	given := 0
+	for _, set := range []bool{in.votes != nil, in.ballots != nil, in.groups != nil} {
+		if set {
+			given++
+		}
+	}
+	if given > 1 {
+		return errors.New("only one of votes, ballots and groups may be given")
+	}
+
+	switch {
+	case in.ballots != nil:
+		if err := validateBallots(sc, in.ballots); err != nil {
+			return err
+		}
+	case in.groups != nil:
+		for i, g := range in.groups {
+			if err := validateVotes(sc, g.Votes); err != nil {
+				return fmt.Errorf("groups[%d] (%s): %w", i, g.Name, err)
+			}
+		}
+	default:
+		return validateVotes(sc, in.votes)
+	}
+
+	if groups := in.chartGroups(); groups != nil {
+		return chart.ValidateGroups(groups)
+	}
+	return nil
+}
+
+// tally combines the votes into the weighted counts the chart package works
+// with.
+func (in voteInput) tally() chart.Tally {
+	if groups := in.chartGroups(); groups != nil {
+		return chart.SumGroups(groups)
+	}
+	if in.ballots != nil {
+		return chart.TallyBallots(chartBallots(in.ballots))
+	}
+	return chart.TallyCounts(in.votes)
+}
+
+// count returns the number of votes cast, which is not their total weight
+// when ballots are weighted.
+func (in voteInput) count() int {
+	if in.ballots != nil {
+		return len(in.ballots)
+	}
+	n := 0
+	for _, c := range in.votes {
+		n += c
+	}
+	for _, g := range in.groups {
+		for _, c := range g.Votes {
+			n += c
+		}
+	}
+	return n
+}
+
+// sampleSize returns the effective number of votes behind tally, which sets
+// the width of the confidence interval; see chart.EffectiveVotes.
+func (in voteInput) sampleSize() float64 {
+	if in.ballots != nil {
+		return chart.EffectiveVotes(chartBallots(in.ballots))
+	}
+	return float64(in.count())
+}
+
+// chartGroups returns the votes split by group, or nil when the request is
+// not grouped. Groups formed from ballots are ordered by first appearance.
+func (in voteInput) chartGroups() []chart.Group {
+	if in.groups != nil {
+		groups := make([]chart.Group, len(in.groups))
+		for i, g := range in.groups {
+			groups[i] = chart.Group{Name: g.Name, Votes: chart.TallyCounts(g.Votes)}
+		}
+		return groups
+	}
+	if len(in.ballots) == 0 || in.ballots[0].Group == "" {
+		return nil
+	}
+
+	var groups []chart.Group
+	index := make(map[string]int)
+	for i, b := range chartBallots(in.ballots) {
+		name := in.ballots[i].Group
+		gi, ok := index[name]
+		if !ok {
+			gi = len(groups)
+			index[name] = gi
+			groups = append(groups, chart.Group{Name: name, Votes: make(chart.Tally)})
+		}
+		groups[gi].Votes[b.Level] += b.Weight
+	}
+	return groups
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    bool
+		errContain string
+	}{
+		{
+			name:       "votes and groups",
+			body:       `{"votes":{"Hard":1},"groups":[{"name":"verified","votes":{"Hard":1}}]}`,
+			wantErr:    true,
+			errContain: "only one of votes, ballots and groups",
+		},
+		{
+			name: "groups",
+			body: `{"groups":[{"name":"verified","votes":{"Hard":3}},{"name":"community","votes":{"Hard +":2,"Hard":1}}]}`,
+		},
+		{
+			name:       "empty groups",
+			body:       `{"groups":[]}`,
+			wantErr:    true,
+			errContain: "no groups",
+		},
+		{
+			name:       "group without votes",
+			body:       `{"groups":[{"name":"verified","votes":{"Hard":3}},{"name":"community","votes":{}}]}`,
+			wantErr:    true,
+			errContain: "groups[1] (community): no votes provided",
+		},
+		{
+			name:       "group with invalid difficulty",
+			body:       `{"groups":[{"name":"verified","votes":{"Harder":3}}]}`,
+			wantErr:    true,
+			errContain: "invalid difficulty: Harder",
+		},
+		{
+			name:       "duplicate group",
+			body:       `{"groups":[{"name":"verified","votes":{"Hard":3}},{"name":"verified","votes":{"Easy":1}}]}`,
+			wantErr:    true,
+			errContain: "duplicate group: verified",
+		},
+		{
+			name:       "unnamed group",
+			body:       `{"groups":[{"votes":{"Hard":3}}]}`,
+			wantErr:    true,
+			errContain: "groups[0]: missing name",
+		},
+		{
+			name:       "too many groups",
+			body:       `{"groups":[{"name":"a","votes":{"Hard":1}},{"name":"b","votes":{"Hard":1}},{"name":"c","votes":{"Hard":1}},{"name":"d","votes":{"Hard":1}},{"name":"e","votes":{"Hard":1}},{"name":"f","votes":{"Hard":1}},{"name":"g","votes":{"Hard":1}}]}`,
+			wantErr:    true,
+			errContain: "too many groups: 7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(tt.body))
+
+			_, err := ParseAndValidate(req)
+			if tt.wantErr {
+				if err == nil || !contains(err.Error(), tt.errContain) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.errContain)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestVoteInputChartGroups(t *testing.T) {
+	in := voteInput{ballots: []Ballot{
+		{Voter: "1", Difficulty: "Hard", Weight: 2, Group: "verified"},
+		{Voter: "2", Difficulty: "Easy", Group: "community"},
+		{Voter: "3", Difficulty: "Hard", Weight: 0.5, Group: "verified"},
+	}}
+	groups := in.chartGroups()
+	if len(groups) != 2 || groups[0].Name != "verified" || groups[1].Name != "community" {
+		t.Fatalf("groups = %+v, want verified then community", groups)
+	}
+	if groups[0].Votes["Hard"] != 2.5 || groups[1].Votes["Easy"] != 1 {
+		t.Errorf("groups = %+v", groups)
+	}
+	if total := in.tally(); total["Hard"] != 2.5 || total["Easy"] != 1 {
+		t.Errorf("tally = %v", total)
+	}
+
+	if groups := (voteInput{ballots: []Ballot{{Voter: "1", Difficulty: "Hard"}}}).chartGroups(); groups != nil {
+		t.Errorf("ungrouped ballots gave groups %+v", groups)
+	}
+}
+
+func TestChartHandlerGroups(t *testing.T) {
+	body := `{"groups":[{"name":"verified","votes":{"Hard":3}},{"name":"community","votes":{"Hard +":2,"Hard":1}}],"format":"png"}`
+	req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	ChartHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestChartHandlerComparison(t *testing.T) {
+	body := `{"votes":{"Hard -":4,"Hard":2},"previous_votes":{"Medium":3,"Medium +":2},"format":"png"}`
+	req := httptest.NewRequest(http.MethodPost, "/chart", bytes.NewBufferString(body))
+
+	rr := httptest.NewRecorder()
+	ChartHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
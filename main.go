@@ -6,16 +6,45 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/genjishimada/playtest-plotter/chart"
 	"github.com/genjishimada/playtest-plotter/handler"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-scale" {
+		os.Exit(validateScaleCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	if backend := os.Getenv("CHART_BACKEND"); backend != "" {
+		if err := chart.SetBackend(backend); err != nil {
+			log.Fatalf("Invalid CHART_BACKEND: %v", err)
+		}
+	}
+	log.Printf("Using %s chart backend", chart.BackendName())
+
+	if path := os.Getenv("CHART_SCALES"); path != "" {
+		loaded, err := chart.LoadScales(path)
+		if err != nil {
+			log.Fatalf("Invalid CHART_SCALES: %v", err)
+		}
+		for _, s := range loaded {
+			chart.RegisterScale(s)
+		}
+	}
+	if err := chart.ValidateScales(); err != nil {
+		log.Fatalf("Invalid difficulty scale:\n%v", err)
+	}
+	log.Printf("Difficulty scales: %v", chart.ScaleNames())
+
 	http.HandleFunc("/chart", handler.ChartHandler)
+	http.HandleFunc("/report", handler.ReportHandler)
+	http.HandleFunc("/stats", handler.StatsHandler)
+	http.HandleFunc("/timeline", handler.TimelineHandler)
 	http.HandleFunc("/health", handler.HealthHandler)
 
 	log.Printf("Starting server on :%s", port)
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/genjishimada/playtest-plotter/chart"
+)
+
+// validateScaleCommand implements `validate-scale FILE...`. It checks every
+// scale in the given files and returns the process exit code.
+func validateScaleCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: playtest-plotter validate-scale FILE...")
+		return 2
+	}
+
+	failed := false
+	for _, path := range args {
+		loaded, err := chart.LoadScales(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			failed = true
+			continue
+		}
+		for _, s := range loaded {
+			if err := s.Validate(); err != nil {
+				fmt.Fprintf(stderr, "%s: %s: invalid\n%v\n", path, s.Name, err)
+				failed = true
+				continue
+			}
+			fmt.Fprintf(stdout, "%s: %s: ok (%d levels)\n", path, s.Name, len(s.Levels))
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
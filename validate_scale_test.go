@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateScaleCommand(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.yaml")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte(`
+scales:
+  - name: arena
+    levels:
+      - {name: Bronze, midpoint: 1, lower: 0, upper: 2, color: "#cd7f32"}
+      - {name: Gold, midpoint: 3, lower: 2, upper: 4, upper_inclusive: true}
+`), 0o644)
+	os.WriteFile(bad, []byte(`{"scales": [{"name": "broken", "levels": [
+		{"name": "Low", "midpoint": 5, "lower": 0, "upper": 2, "color": "#000"},
+		{"name": "High", "midpoint": 6, "lower": 3, "upper": 4, "upper_inclusive": true}
+	]}]}`), 0o644)
+
+	tests := []struct {
+		name       string
+		args       []string
+		code       int
+		wantStdout string
+		wantStderr []string
+	}{
+		{"no args", nil, 2, "", []string{"usage:"}},
+		{"valid file", []string{good}, 0, "arena: ok (2 levels)", nil},
+		{
+			name:       "invalid file",
+			args:       []string{good, bad},
+			code:       1,
+			wantStdout: "arena: ok",
+			wantStderr: []string{"broken: invalid", `"Low": midpoint 5 outside`, "gap between"},
+		},
+		{"missing file", []string{filepath.Join(dir, "nope.yaml")}, 1, "", []string{"nope.yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := validateScaleCommand(tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d (stderr: %s)", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout %q should contain %q", stdout.String(), tt.wantStdout)
+			}
+			for _, want := range tt.wantStderr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr %q should contain %q", stderr.String(), want)
+				}
+			}
+		})
+	}
+}